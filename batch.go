@@ -0,0 +1,95 @@
+package wikipedia
+
+import "context"
+import "errors"
+import "strings"
+
+const batchSize = 50
+
+type PageResult struct {
+	Title    string
+	Snapshot *PageSnapshot
+	Err      error
+}
+
+func (w *WikipediaClient) Pages(titles ...string) ([]PageResult, error) {
+	return w.PagesContext(context.Background(), titles...)
+}
+
+func (w *WikipediaClient) PagesContext(ctx context.Context, titles ...string) ([]PageResult, error) {
+	results := make([]PageResult, 0, len(titles))
+	for start := 0; start < len(titles); start += batchSize {
+		batch, err := w.loadPages(ctx, titles[start:min(start+batchSize, len(titles))])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+func (w *WikipediaClient) loadPages(ctx context.Context, titles []string) ([]PageResult, error) {
+	opts := LoadOptions{Categories: true, SummaryOnly: true}
+	snapshots := make(map[string]*PageSnapshot)
+	pageErrors := make(map[string]error)
+	var first *apiQuery
+	params := map[string][]string{"continue": {""}}
+	for {
+		for k, v := range snapshotParams(opts) {
+			params[k] = v
+		}
+		params["titles"] = []string{strings.Join(titles, "|")}
+		if w.FollowRedirects() {
+			params["redirects"] = []string{""}
+		}
+		var r apiResponse
+		err := query(ctx, w, params, &r)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = r.Query
+		}
+		for i := range r.pages() {
+			p := &r.Query.Pages[i]
+			if err := p.err(); err != nil {
+				pageErrors[p.Title] = err
+				continue
+			}
+			if snapshots[p.Title] == nil {
+				snapshots[p.Title] = newSnapshot(opts)
+			}
+			snapshots[p.Title].merge(p)
+		}
+		params = parseCont(&r)
+		if len(params) == 0 {
+			break
+		}
+	}
+
+	results := make([]PageResult, 0, len(titles))
+	for _, title := range titles {
+		result := PageResult{Title: title}
+		redirects, err := resolveRedirects(first, title)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+		target := title
+		if len(redirects) > 0 {
+			target = redirects[len(redirects)-1].To
+		}
+		if snapshot, ok := snapshots[target]; ok {
+			s := *snapshot
+			s.Redirects = redirects
+			result.Snapshot = &s
+		} else if err, ok := pageErrors[target]; ok {
+			result.Err = err
+		} else {
+			result.Err = newError(ResponseError, errors.New("page not found in response"))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package wikipedia
+
+import "encoding/json"
+import "errors"
+import "fmt"
+import "net/http"
+import "strings"
+import "testing"
+
+func TestPages(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		titles := strings.Split(r.URL.Query().Get("titles"), "|")
+		if len(titles) > batchSize {
+			t.Error(fmt.Sprintf("got %d titles in one request", len(titles)))
+		}
+		pages := make([]interface{}, 0)
+		normalized := make([]interface{}, 0)
+		redirects := make([]interface{}, 0)
+		for i, title := range titles {
+			switch title {
+			case "bikeshedding":
+				normalized = append(normalized, map[string]string{"from": "bikeshedding", "to": "Bikeshedding"})
+				redirects = append(redirects, map[string]string{"from": "Bikeshedding", "to": "Law of triviality"})
+				title = "Law of triviality"
+			case "Nope":
+				pages = append(pages, map[string]interface{}{"title": title, "missing": true})
+				continue
+			}
+			pages = append(pages, map[string]interface{}{"pageid": i + 1, "title": title, "extract": "About " + title, "categories": []interface{}{map[string]string{"title": "Category:" + title}}})
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{"query": map[string]interface{}{"normalized": normalized, "redirects": redirects, "pages": pages}})
+	})
+	titles := []string{"bikeshedding", "Nope"}
+	for i := 0; i < 70; i++ {
+		titles = append(titles, fmt.Sprintf("Page %d", i))
+	}
+	results, err := w.Pages(titles...)
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting pages %s", err))
+		return
+	}
+	if requests != 2 {
+		t.Error(fmt.Sprintf("expected 2 requests, got %d", requests))
+		return
+	}
+	if len(results) != len(titles) {
+		t.Error("got wrong number of results")
+		return
+	}
+	if results[0].Title != "bikeshedding" || results[0].Snapshot == nil || results[0].Snapshot.Title != "Law of triviality" || len(results[0].Snapshot.Redirects) != 2 {
+		t.Error("expected bikeshedding to resolve to Law of triviality")
+		return
+	}
+	if errors.Is(results[1].Err, ErrMissingPage) == false {
+		t.Error("expected Nope to be missing")
+		return
+	}
+	last := results[len(results)-1]
+	if last.Err != nil || last.Snapshot.Intro != "About Page 69" || len(last.Snapshot.Categories) != 1 {
+		t.Error("got wrong last result")
+		return
+	}
+}
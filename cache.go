@@ -0,0 +1,384 @@
+package wikipedia
+
+import "container/list"
+import "context"
+import "crypto/sha256"
+import "encoding/hex"
+import "encoding/json"
+import "net/url"
+import "os"
+import "path/filepath"
+import "slices"
+import "sort"
+import "strings"
+import "sync"
+import "time"
+
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+var unorderedParameters = map[string]bool{
+	"prop":    true,
+	"list":    true,
+	"meta":    true,
+	"titles":  true,
+	"pageids": true,
+	"revids":  true,
+	"inprop":  true,
+	"iiprop":  true,
+	"rvprop":  true,
+	"siprop":  true,
+	"ppprop":  true,
+}
+
+func cacheKey(baseUrl string, q map[string][]string) string {
+	normalized := make(url.Values, len(q))
+	for k, values := range q {
+		for _, value := range values {
+			if unorderedParameters[k] {
+				parts := strings.Split(value, "|")
+				sort.Strings(parts)
+				value = strings.Join(parts, "|")
+			}
+			normalized.Add(k, value)
+		}
+	}
+	return baseUrl + "?" + normalized.Encode()
+}
+
+func cacheable(q map[string][]string) bool {
+	for _, value := range q["list"] {
+		if strings.Contains(value, "random") {
+			return false
+		}
+	}
+	for _, value := range q["generator"] {
+		if value == "random" {
+			return false
+		}
+	}
+	return true
+}
+
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryCache(size int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.entries.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.entries.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &memoryCacheEntry{key: key, value: value, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.entries.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.entries.PushFront(entry)
+	for c.size > 0 && c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.items, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.entries.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewDiskCache(dir string, ttl time.Duration) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, ttl: ttl}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path)
+		return nil, false
+	}
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *DiskCache) Set(key string, value []byte) {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(value)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
+
+const maxTrackedTitles = 10000
+const maxKeysPerTitle = 100
+
+type revisionCache struct {
+	Cache
+	mu      sync.Mutex
+	titles  *list.List
+	tracked map[string]*list.Element
+	entries map[string][]string
+}
+
+type trackedTitle struct {
+	title    string
+	revision int64
+	checked  time.Time
+	keys     []string
+}
+
+type revisionPage struct {
+	Title     string `json:"title"`
+	LastRevId int64  `json:"lastrevid"`
+	Revisions []struct {
+		RevId int64 `json:"revid"`
+	} `json:"revisions"`
+}
+
+type revisionResponse struct {
+	Query struct {
+		Pages []revisionPage `json:"pages"`
+	} `json:"query"`
+	Parse *struct {
+		Title string `json:"title"`
+		RevId int64  `json:"revid"`
+	} `json:"parse"`
+}
+
+func (r *revisionResponse) pages() []revisionPage {
+	pages := r.Query.Pages
+	if r.Parse != nil {
+		pages = append(pages, revisionPage{Title: r.Parse.Title, LastRevId: r.Parse.RevId})
+	}
+	return pages
+}
+
+func newRevisionCache(cache Cache) Cache {
+	if cache == nil {
+		return nil
+	}
+	return &revisionCache{
+		Cache:   cache,
+		titles:  list.New(),
+		tracked: make(map[string]*list.Element),
+		entries: make(map[string][]string),
+	}
+}
+
+func (c *revisionCache) Get(key string) ([]byte, bool) {
+	value, ok := c.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, known := c.entries[key]; !known {
+		c.track(key, value, time.Time{})
+	}
+	return value, true
+}
+
+func (c *revisionCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	c.track(key, value, time.Now())
+	c.mu.Unlock()
+	c.Cache.Set(key, value)
+}
+
+func (c *revisionCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+func (c *revisionCache) track(key string, value []byte, checked time.Time) {
+	var response revisionResponse
+	json.Unmarshal(value, &response)
+	pages := response.pages()
+	titles := make([]string, 0, len(pages))
+	for _, page := range pages {
+		if page.Title == "" {
+			continue
+		}
+		t := c.title(page.Title)
+		revision := page.LastRevId
+		if len(page.Revisions) > 0 && page.Revisions[0].RevId > revision {
+			revision = page.Revisions[0].RevId
+		}
+		if revision > t.revision {
+			if t.revision != 0 {
+				c.invalidate(t)
+			}
+			t.revision = revision
+			t.checked = checked
+		}
+		if !slices.Contains(t.keys, key) {
+			t.keys = append(t.keys, key)
+		}
+		if len(t.keys) > maxKeysPerTitle {
+			c.remove(t.keys[0])
+		}
+		titles = append(titles, page.Title)
+	}
+	if len(titles) > 0 {
+		c.entries[key] = titles
+	}
+}
+
+func (c *revisionCache) title(title string) *trackedTitle {
+	if el, ok := c.tracked[title]; ok {
+		c.titles.MoveToFront(el)
+		return el.Value.(*trackedTitle)
+	}
+	t := &trackedTitle{title: title}
+	c.tracked[title] = c.titles.PushFront(t)
+	for c.titles.Len() > maxTrackedTitles {
+		oldest := c.titles.Back().Value.(*trackedTitle)
+		c.invalidate(oldest)
+		c.titles.Remove(c.titles.Back())
+		delete(c.tracked, oldest.title)
+	}
+	return t
+}
+
+func (c *revisionCache) invalidate(t *trackedTitle) {
+	for len(t.keys) > 0 {
+		c.remove(t.keys[0])
+	}
+}
+
+func (c *revisionCache) remove(key string) {
+	for _, title := range c.entries[key] {
+		if el, ok := c.tracked[title]; ok {
+			t := el.Value.(*trackedTitle)
+			t.keys = slices.DeleteFunc(t.keys, func(k string) bool {
+				return k == key
+			})
+		}
+	}
+	delete(c.entries, key)
+	c.Cache.Delete(key)
+}
+
+func (c *revisionCache) revalidate(ctx context.Context, w Wikipedia, key string) bool {
+	c.mu.Lock()
+	titles := make([]string, 0)
+	for _, title := range c.entries[key] {
+		if el, ok := c.tracked[title]; ok && time.Since(el.Value.(*trackedTitle).checked) >= w.CacheRevalidation() {
+			titles = append(titles, title)
+		}
+	}
+	c.mu.Unlock()
+
+	fresh := true
+	for start := 0; start < len(titles); start += 50 {
+		body, err := fetch(ctx, w, map[string][]string{
+			"titles": {strings.Join(titles[start:min(start+50, len(titles))], "|")},
+			"prop":   {"info"},
+			"format": {"json"},
+			"action": {"query"},
+		})
+		if err != nil {
+			return fresh
+		}
+		var response revisionResponse
+		if json.Unmarshal(body, &response) != nil {
+			return fresh
+		}
+		c.mu.Lock()
+		for _, page := range response.Query.Pages {
+			el, ok := c.tracked[page.Title]
+			if !ok {
+				continue
+			}
+			t := el.Value.(*trackedTitle)
+			t.checked = time.Now()
+			if page.LastRevId != t.revision {
+				c.invalidate(t)
+				t.revision = page.LastRevId
+				fresh = false
+			}
+		}
+		c.mu.Unlock()
+	}
+	return fresh
+}
@@ -0,0 +1,170 @@
+package wikipedia
+
+import "fmt"
+import "net/http"
+import "os"
+import "strings"
+import "sync"
+import "testing"
+import "time"
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	cache := NewMemoryCache(2, time.Hour)
+	cache.Set("a", []byte("1"))
+	cache.Set("b", []byte("2"))
+	cache.Get("a")
+	cache.Set("c", []byte("3"))
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+		return
+	}
+	if value, ok := cache.Get("a"); !ok || string(value) != "1" {
+		t.Error("expected a to be cached")
+		return
+	}
+
+	cache = NewMemoryCache(2, time.Nanosecond)
+	cache.Set("a", []byte("1"))
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected entry to expire")
+		return
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, time.Hour)
+	if err != nil {
+		t.Error(fmt.Sprintf("error creating disk cache %s", err))
+		return
+	}
+	cache.Set("a", []byte("1"))
+	if value, ok := cache.Get("a"); !ok || string(value) != "1" {
+		t.Error("expected a to be cached")
+		return
+	}
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected a to be deleted")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Set("b", []byte(strings.Repeat("2", 4096)))
+		}()
+	}
+	wg.Wait()
+	if value, ok := cache.Get("b"); !ok || string(value) != strings.Repeat("2", 4096) {
+		t.Error("expected concurrent writes to leave a complete entry")
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Error(fmt.Sprintf("expected no temporary files to be left, got %d entries", len(entries)))
+		return
+	}
+}
+
+func TestCacheKeyNormalization(t *testing.T) {
+	t.Parallel()
+	a := cacheKey("http://x", map[string][]string{"prop": {"info|pageprops"}, "titles": {"A"}})
+	b := cacheKey("http://x", map[string][]string{"titles": {"A"}, "prop": {"pageprops|info"}})
+	if a != b {
+		t.Error("expected equivalent parameters to share a cache key")
+		return
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	revision := 1
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("prop") {
+		case "info":
+			fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"A","lastrevid":%d}]}}`, revision)
+		case "extracts":
+			fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"A","extract":"summary %d"}]}}`, revision)
+		default:
+			fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"A","extract":"content %d","revisions":[{"revid":%d}]}]}}`, revision, revision)
+		}
+	}, WithCache(NewMemoryCache(100, time.Hour)), WithCacheRevalidation(0))
+	page := NewPage(w, "A")
+	for i := 0; i < 2; i++ {
+		summary, err := page.Summary()
+		if err != nil || summary != "summary 1" {
+			t.Error(fmt.Sprintf("got wrong summary %q", summary))
+			return
+		}
+		content, err := page.Content()
+		if err != nil || content != "content 1" {
+			t.Error(fmt.Sprintf("got wrong content %q", content))
+			return
+		}
+	}
+	if requests != 4 {
+		t.Error(fmt.Sprintf("expected 4 requests, got %d", requests))
+		return
+	}
+
+	revision = 2
+	content, err := page.Content()
+	if err != nil || content != "content 2" {
+		t.Error(fmt.Sprintf("got wrong content %q", content))
+		return
+	}
+	summary, err := page.Summary()
+	if err != nil || summary != "summary 2" {
+		t.Error(fmt.Sprintf("expected newer revision to invalidate cached summary, got %q", summary))
+		return
+	}
+	if requests != 7 {
+		t.Error(fmt.Sprintf("expected 7 requests, got %d", requests))
+		return
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	revision := 1
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("action") == "query" {
+			fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"A","lastrevid":%d}]}}`, revision)
+			return
+		}
+		fmt.Fprintf(rw, `{"parse":{"title":"A","pageid":1,"revid":%d,"sections":[{"toclevel":1,"level":"2","line":"Section %d","number":"1","index":"1","byteoffset":0,"anchor":"Section_%d"}]}}`, revision, revision, revision)
+	}, WithCache(NewMemoryCache(100, 0)), WithCacheRevalidation(0))
+	page := NewPage(w, "A")
+	for i := 0; i < 2; i++ {
+		sections, err := page.Sections()
+		if err != nil || len(sections) != 1 || sections[0] != "Section 1" {
+			t.Error(fmt.Sprintf("got wrong sections %v", sections))
+			return
+		}
+	}
+	if requests != 2 {
+		t.Error(fmt.Sprintf("expected 2 requests, got %d", requests))
+		return
+	}
+
+	revision = 2
+	sections, err := page.Sections()
+	if err != nil || len(sections) != 1 || sections[0] != "Section 2" {
+		t.Error(fmt.Sprintf("expected newer revision to invalidate cached sections, got %v", sections))
+		return
+	}
+	if requests != 4 {
+		t.Error(fmt.Sprintf("expected 4 requests, got %d", requests))
+		return
+	}
+}
@@ -0,0 +1,240 @@
+package wikipedia
+
+import "context"
+import "regexp"
+import "slices"
+import "strconv"
+import "strings"
+
+import "github.com/seppo0010/wikipedia-go/wikitext"
+
+type CitationUse struct {
+	Section, Sentence string
+}
+
+type Citation struct {
+	Name, Template   string
+	Raw, Text        string
+	Fields           map[string]string
+	Title, Date, Url string
+	Doi, Isbn        string
+	ArchiveUrl       string
+	Authors          []string
+	Sections         []string
+	Uses             []CitationUse
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?]['"”’)]*$`)
+var sentenceBreak = regexp.MustCompile(`[.!?]['"”’)]*(?:\s|$)|\n`)
+
+const LeadSection = "(Top)"
+
+type citationUse struct {
+	citation *Citation
+	offset   int
+	section  string
+	sentence string
+}
+
+type citationWalker struct {
+	doc       *wikitext.Document
+	section   string
+	text      strings.Builder
+	uses      []citationUse
+	named     map[string]*Citation
+	citations []*Citation
+}
+
+func isCitationTemplate(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "cite ") || strings.HasPrefix(name, "vcite ") || name == "citation"
+}
+
+func firstField(fields map[string]string, names ...string) string {
+	for _, name := range names {
+		if fields[name] != "" {
+			return fields[name]
+		}
+	}
+	return ""
+}
+
+func citationAuthors(fields map[string]string) []string {
+	authors := make([]string, 0)
+	for i := 1; ; i++ {
+		names := func(name string) []string {
+			if i == 1 {
+				return []string{name, name + "1"}
+			}
+			return []string{name + strconv.Itoa(i)}
+		}
+		author := firstField(fields, append(names("author"), names("authors")...)...)
+		if last := firstField(fields, append(names("last"), names("surname")...)...); last != "" {
+			author = last
+			if first := firstField(fields, append(names("first"), names("given")...)...); first != "" {
+				author += ", " + first
+			}
+		}
+		if author == "" {
+			return authors
+		}
+		authors = append(authors, author)
+	}
+}
+
+func (w *citationWalker) define(citation *Citation, tag *wikitext.Tag) {
+	if len(tag.Nodes) == 0 || citation.Raw != "" {
+		return
+	}
+	start, end := tag.Nodes[0].Pos().Offset, tag.Nodes[len(tag.Nodes)-1].End().Offset
+	citation.Raw = strings.TrimSpace(w.doc.Source[start:end])
+	citation.Text = templateText(tag.Nodes)
+	wikitext.Inspect(tag.Nodes, func(node wikitext.Node) bool {
+		template, ok := node.(*wikitext.Template)
+		if !ok || citation.Template != "" || !isCitationTemplate(template.Name) {
+			return citation.Template == ""
+		}
+		citation.Template = template.Name
+		for _, param := range template.Params {
+			if !param.Positional {
+				citation.Fields[param.Name] = templateText(param.Value)
+			}
+		}
+		return false
+	})
+	citation.Title = firstField(citation.Fields, "title", "chapter")
+	citation.Date = firstField(citation.Fields, "date", "year")
+	citation.Url = firstField(citation.Fields, "url", "chapter-url")
+	citation.Doi = firstField(citation.Fields, "doi", "DOI")
+	citation.Isbn = firstField(citation.Fields, "isbn", "ISBN")
+	citation.ArchiveUrl = firstField(citation.Fields, "archive-url", "archiveurl")
+	citation.Authors = citationAuthors(citation.Fields)
+}
+
+func (w *citationWalker) ref(tag *wikitext.Tag, use bool, sentence string) {
+	name := strings.TrimSpace(tag.Attrs["name"])
+	citation := w.named[name]
+	if citation == nil {
+		citation = &Citation{Name: name, Fields: make(map[string]string), Sections: make([]string, 0), Uses: make([]CitationUse, 0)}
+		w.citations = append(w.citations, citation)
+		if name != "" {
+			w.named[name] = citation
+		}
+	}
+	w.define(citation, tag)
+	if use {
+		w.uses = append(w.uses, citationUse{citation: citation, offset: w.text.Len(), section: w.section, sentence: sentence})
+	}
+}
+
+func (w *citationWalker) silent(nodes []wikitext.Node, sentence string) {
+	wikitext.Inspect(nodes, func(node wikitext.Node) bool {
+		if tag, ok := node.(*wikitext.Tag); ok && tag.Name == "ref" {
+			w.ref(tag, true, sentence)
+			return false
+		}
+		return true
+	})
+}
+
+func (w *citationWalker) walk(nodes []wikitext.Node) {
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *wikitext.Text:
+			w.text.WriteString(wikitext.StripEmphasis(node.Value))
+		case *wikitext.Heading:
+			w.section = wikitext.PlainText(node.Nodes)
+			w.text.WriteString("\n")
+		case *wikitext.Link, *wikitext.ExternalLink:
+			w.text.WriteString(wikitext.PlainText([]wikitext.Node{node}))
+		case *wikitext.Template:
+			w.text.WriteString(renderTemplate(node))
+			for _, param := range node.Params {
+				w.silent(param.Value, templateText(param.Value))
+			}
+		case *wikitext.Tag:
+			switch node.Name {
+			case "ref":
+				w.ref(node, true, "")
+			case "references":
+				wikitext.Inspect(node.Nodes, func(node wikitext.Node) bool {
+					if tag, ok := node.(*wikitext.Tag); ok && tag.Name == "ref" {
+						w.ref(tag, false, "")
+						return false
+					}
+					return true
+				})
+			case "br":
+				w.text.WriteString("\n")
+			default:
+				w.walk(node.Nodes)
+			}
+		case *wikitext.List:
+			for _, item := range node.Items {
+				w.text.WriteString("\n")
+				w.walk(item.Nodes)
+			}
+			w.text.WriteString("\n")
+		case *wikitext.Table:
+			for _, row := range node.Rows {
+				for _, cell := range row.Cells {
+					w.text.WriteString("\n")
+					w.walk(cell.Nodes)
+				}
+			}
+			w.text.WriteString("\n")
+		}
+	}
+}
+
+func sentenceAt(text string, offset int) string {
+	before := strings.TrimRight(text[:offset], " \t")
+	end := offset
+	if match := sentenceEnd.FindStringIndex(before); match != nil {
+		end = len(before)
+		before = before[:match[0]]
+	} else if match := sentenceBreak.FindStringSubmatchIndex(text[offset:]); match != nil {
+		end = offset + match[1]
+	} else {
+		end = len(text)
+	}
+	start := 0
+	if breaks := sentenceBreak.FindAllStringIndex(before, -1); len(breaks) > 0 {
+		start = breaks[len(breaks)-1][1]
+	}
+	return strings.Join(strings.Fields(text[start:end]), " ")
+}
+
+func findCitations(doc *wikitext.Document) []Citation {
+	w := &citationWalker{doc: doc, section: LeadSection, named: make(map[string]*Citation)}
+	w.walk(doc.Nodes)
+	text := w.text.String()
+	for _, use := range w.uses {
+		sentence := use.sentence
+		if sentence == "" {
+			sentence = sentenceAt(text, use.offset)
+		}
+		citation := use.citation
+		citation.Uses = append(citation.Uses, CitationUse{Section: use.section, Sentence: sentence})
+		if !slices.Contains(citation.Sections, use.section) {
+			citation.Sections = append(citation.Sections, use.section)
+		}
+	}
+	citations := make([]Citation, 0, len(w.citations))
+	for _, citation := range w.citations {
+		citations = append(citations, *citation)
+	}
+	return citations
+}
+
+func (page *PageClient) References() ([]Citation, error) {
+	return page.ReferencesContext(context.Background())
+}
+
+func (page *PageClient) ReferencesContext(ctx context.Context) ([]Citation, error) {
+	doc, err := page.ParseWikitextContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findCitations(doc), nil
+}
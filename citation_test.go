@@ -0,0 +1,80 @@
+package wikipedia
+
+import "encoding/json"
+import "fmt"
+import "net/http"
+import "testing"
+
+const citationWikitext = `{{Infobox person
+| name = Ada Lovelace
+| birth_date = 10 December 1815<ref name="birth">{{cite web |url=https://example.com/birth |title=Birth record |date=2001-05-04 |archive-url=https://archive.org/birth}}</ref>
+}}
+'''Ada Lovelace''' was an English [[mathematician]].<ref name="toole">{{cite book |last=Toole |first=Betty |author2=Someone Else |title=''Ada, the Enchantress of Numbers'' |year=1998 |isbn=978-0912647180}}</ref> She is often regarded as the first programmer.
+
+== Early life ==
+She was born in [[London]], England<ref name="birth" /> to a famous poet. Her mother promoted her interest in mathematics.<ref>{{Cite journal |title=Math |doi=10.1000/xyz}}</ref>
+
+== Legacy ==
+Her notes are widely cited.<ref name="toole" /><ref name="notes" />
+
+== References ==
+<references>
+<ref name="notes">Plain note with no template.</ref>
+</references>`
+
+func TestReferences(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		content, _ := json.Marshal(citationWikitext)
+		fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"Ada Lovelace","revisions":[{"revid":42,"slots":{"main":{"contentmodel":"wikitext","content":%s}}}]}]}}`, content)
+	})
+	citations, err := NewPage(w, "Ada Lovelace").References()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting references %s", err))
+		return
+	}
+	if len(citations) != 4 {
+		t.Error(fmt.Sprintf("expected 4 citations, got %+v", citations))
+		return
+	}
+
+	birth := citations[0]
+	if birth.Name != "birth" || birth.Template != "cite web" || birth.Title != "Birth record" || birth.Date != "2001-05-04" || birth.Url != "https://example.com/birth" || birth.ArchiveUrl != "https://archive.org/birth" {
+		t.Error(fmt.Sprintf("got wrong birth citation %+v", birth))
+		return
+	}
+	if len(birth.Uses) != 2 || birth.Uses[0].Section != LeadSection || birth.Uses[0].Sentence != "10 December 1815" {
+		t.Error(fmt.Sprintf("got wrong birth uses %+v", birth.Uses))
+		return
+	}
+	if birth.Uses[1].Section != "Early life" || birth.Uses[1].Sentence != "She was born in London, England to a famous poet." {
+		t.Error(fmt.Sprintf("got wrong birth uses %+v", birth.Uses))
+		return
+	}
+
+	toole := citations[1]
+	if toole.Title != "Ada, the Enchantress of Numbers" || toole.Isbn != "978-0912647180" || toole.Date != "1998" {
+		t.Error(fmt.Sprintf("got wrong toole citation %+v", toole))
+		return
+	}
+	if len(toole.Authors) != 2 || toole.Authors[0] != "Toole, Betty" || toole.Authors[1] != "Someone Else" {
+		t.Error(fmt.Sprintf("got wrong authors %+v", toole.Authors))
+		return
+	}
+	if fmt.Sprint(toole.Sections) != "[(Top) Legacy]" || toole.Uses[0].Sentence != "Ada Lovelace was an English mathematician." || toole.Uses[1].Sentence != "Her notes are widely cited." {
+		t.Error(fmt.Sprintf("got wrong toole uses %+v %+v", toole.Sections, toole.Uses))
+		return
+	}
+
+	journal := citations[2]
+	if journal.Name != "" || journal.Doi != "10.1000/xyz" || journal.Uses[0].Sentence != "Her mother promoted her interest in mathematics." {
+		t.Error(fmt.Sprintf("got wrong journal citation %+v", journal))
+		return
+	}
+
+	notes := citations[3]
+	if notes.Text != "Plain note with no template." || notes.Template != "" || len(notes.Uses) != 1 || notes.Sections[0] != "Legacy" {
+		t.Error(fmt.Sprintf("got wrong notes citation %+v", notes))
+		return
+	}
+}
@@ -0,0 +1,60 @@
+package wikipedia
+
+import "context"
+import "iter"
+
+type Cursor struct {
+	Continue map[string]string `json:"continue,omitempty"`
+	Offset   int               `json:"offset"`
+	Done     bool              `json:"done"`
+}
+
+func (cursor *Cursor) params() map[string][]string {
+	params := make(map[string][]string, len(cursor.Continue))
+	for k, v := range cursor.Continue {
+		params[k] = []string{v}
+	}
+	return params
+}
+
+func (cursor *Cursor) advance(cont map[string][]string) {
+	cursor.Offset = 0
+	if len(cont) == 0 {
+		cursor.Continue = nil
+		cursor.Done = true
+		return
+	}
+	cursor.Continue = make(map[string]string, len(cont))
+	for k, v := range cont {
+		if len(v) > 0 {
+			cursor.Continue[k] = v[0]
+		}
+	}
+}
+
+func resume[T any](ctx context.Context, cursor *Cursor, request func(context.Context, map[string][]string) ([]T, map[string][]string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for !cursor.Done {
+			items, cont, err := request(ctx, cursor.params())
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if cursor.Offset >= len(items) {
+				cursor.advance(cont)
+				continue
+			}
+			for i := cursor.Offset; i < len(items); i++ {
+				if i == len(items)-1 {
+					cursor.advance(cont)
+				} else {
+					cursor.Offset = i + 1
+				}
+				if !yield(items[i], nil) {
+					return
+				}
+			}
+		}
+	}
+}
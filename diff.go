@@ -0,0 +1,378 @@
+package wikipedia
+
+import "context"
+import "encoding/xml"
+import "regexp"
+import "slices"
+import "strconv"
+import "strings"
+
+type DiffOp int
+
+const (
+	Equal DiffOp = iota
+	Insert
+	Delete
+	Change
+)
+
+type WordDiff struct {
+	Op   DiffOp
+	Text string
+}
+
+type Hunk struct {
+	Op               DiffOp
+	Section          string
+	OldLine, NewLine int
+	Old, New         []string
+	Words            []WordDiff
+}
+
+type Diff struct {
+	FromRev, ToRev int64
+	Hunks          []Hunk
+}
+
+type SectionDiff struct {
+	Section string
+	Hunks   []Hunk
+}
+
+func (diff *Diff) BySection() []SectionDiff {
+	sections := make([]SectionDiff, 0)
+	index := make(map[string]int)
+	for _, hunk := range diff.Hunks {
+		i, ok := index[hunk.Section]
+		if !ok {
+			i = len(sections)
+			index[hunk.Section] = i
+			sections = append(sections, SectionDiff{Section: hunk.Section})
+		}
+		sections[i].Hunks = append(sections[i].Hunks, hunk)
+	}
+	return sections
+}
+
+var headingLine = regexp.MustCompile(`^(=+)\s*(.*?)\s*(=+)\s*$`)
+var wordTokens = regexp.MustCompile(`\s+|[^\s]+`)
+var lineNumber = regexp.MustCompile(`\d[\d,.\x{a0}\x{202f} ]*`)
+
+func sectionAt(lines []string, line int) string {
+	section := ""
+	for i := 0; i < line && i < len(lines); i++ {
+		if match := headingLine.FindStringSubmatch(lines[i]); match != nil && len(match[1]) == len(match[3]) && len(match[1]) > 1 {
+			section = match[2]
+		}
+	}
+	return section
+}
+
+const maxDiffEdits = 1000
+
+func diffTokens(a, b []string) []WordDiff {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := make([]WordDiff, 0, len(a)+len(b))
+	for _, token := range a[:prefix] {
+		ops = append(ops, WordDiff{Op: Equal, Text: token})
+	}
+	ops = append(ops, editScript(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, token := range a[len(a)-suffix:] {
+		ops = append(ops, WordDiff{Op: Equal, Text: token})
+	}
+	return ops
+}
+
+func editScript(x, y []string) []WordDiff {
+	limit := min(len(x)+len(y), maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	trace := make([][]int, 0)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < len(x) && j < len(y) && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= len(x) && j >= len(y) {
+				trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+				return backtrack(x, y, trace)
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+	}
+
+	ops := make([]WordDiff, 0, len(x)+len(y))
+	for _, token := range x {
+		ops = append(ops, WordDiff{Op: Delete, Text: token})
+	}
+	for _, token := range y {
+		ops = append(ops, WordDiff{Op: Insert, Text: token})
+	}
+	return ops
+}
+
+func backtrack(x, y []string, trace [][]int) []WordDiff {
+	furthest := func(d, k int) int {
+		return trace[d][k+d]
+	}
+	ops := make([]WordDiff, 0, len(x)+len(y))
+	i, j := len(x), len(y)
+	for d := len(trace) - 1; d > 0; d-- {
+		k := i - j
+		previous := k - 1
+		if k == -d || (k != d && furthest(d-1, k-1) < furthest(d-1, k+1)) {
+			previous = k + 1
+		}
+		previousI := furthest(d-1, previous)
+		previousJ := previousI - previous
+		for i > previousI && j > previousJ {
+			ops = append(ops, WordDiff{Op: Equal, Text: x[i-1]})
+			i--
+			j--
+		}
+		if previous == k+1 {
+			ops = append(ops, WordDiff{Op: Insert, Text: y[j-1]})
+			j--
+		} else {
+			ops = append(ops, WordDiff{Op: Delete, Text: x[i-1]})
+			i--
+		}
+	}
+	for i > 0 && j > 0 {
+		ops = append(ops, WordDiff{Op: Equal, Text: x[i-1]})
+		i--
+		j--
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+func mergeWords(ops []WordDiff) []WordDiff {
+	merged := make([]WordDiff, 0, len(ops))
+	for _, op := range ops {
+		if len(merged) > 0 && merged[len(merged)-1].Op == op.Op {
+			merged[len(merged)-1].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+	return merged
+}
+
+func DiffWords(old, new string) []WordDiff {
+	return mergeWords(diffTokens(wordTokens.FindAllString(old, -1), wordTokens.FindAllString(new, -1)))
+}
+
+func newHunk(old, new []string, oldLine, newLine int) Hunk {
+	hunk := Hunk{Op: Change, OldLine: oldLine, NewLine: newLine, Old: old, New: new}
+	switch {
+	case len(old) == 0:
+		hunk.Op = Insert
+	case len(new) == 0:
+		hunk.Op = Delete
+	default:
+		hunk.Words = DiffWords(strings.Join(old, "\n"), strings.Join(new, "\n"))
+	}
+	return hunk
+}
+
+func DiffText(old, new string) []Hunk {
+	oldLines, newLines := strings.Split(old, "\n"), strings.Split(new, "\n")
+	hunks := make([]Hunk, 0)
+	var deleted, inserted []string
+	oldLine, newLine := 1, 1
+	flush := func() {
+		if len(deleted) > 0 || len(inserted) > 0 {
+			hunk := newHunk(deleted, inserted, oldLine-len(deleted), newLine-len(inserted))
+			if hunk.Op == Delete {
+				hunk.Section = sectionAt(oldLines, hunk.OldLine)
+			} else {
+				hunk.Section = sectionAt(newLines, hunk.NewLine)
+			}
+			hunks = append(hunks, hunk)
+		}
+		deleted, inserted = nil, nil
+	}
+	for _, op := range diffTokens(oldLines, newLines) {
+		switch op.Op {
+		case Equal:
+			flush()
+			oldLine++
+			newLine++
+		case Delete:
+			deleted = append(deleted, op.Text)
+			oldLine++
+		case Insert:
+			inserted = append(inserted, op.Text)
+			newLine++
+		}
+	}
+	flush()
+	return hunks
+}
+
+type diffCell struct {
+	class, text string
+}
+
+func parseLineNumber(text string) int {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, lineNumber.FindString(text))
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+func parseDiffTable(body string, newLines []string) []Hunk {
+	decoder := newHtmlDecoder("<table>" + body + "</table>")
+	rows := make([][]diffCell, 0)
+	var row []diffCell
+	var cell *diffCell
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "tr":
+				row = make([]diffCell, 0)
+			case "td":
+				cell = &diffCell{class: attr(token, "class")}
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "td":
+				if cell != nil {
+					row = append(row, *cell)
+					cell = nil
+				}
+			case "tr":
+				rows = append(rows, row)
+			}
+		case xml.CharData:
+			if cell != nil {
+				cell.text += string(token)
+			}
+		}
+	}
+
+	hunks := make([]Hunk, 0)
+	var deleted, inserted []string
+	oldLine, newLine := 1, 1
+	kind := Equal
+	flush := func() {
+		if len(deleted) > 0 || len(inserted) > 0 {
+			hunk := newHunk(deleted, inserted, oldLine-len(deleted), newLine-len(inserted))
+			hunk.Section = sectionAt(newLines, hunk.NewLine)
+			hunks = append(hunks, hunk)
+		}
+		deleted, inserted = nil, nil
+	}
+	for _, row := range rows {
+		numbers := make([]int, 0, 2)
+		var old, new *diffCell
+		context := false
+		for i := range row {
+			for _, class := range strings.Fields(row[i].class) {
+				switch class {
+				case "diff-lineno":
+					numbers = append(numbers, parseLineNumber(row[i].text))
+				case "diff-deletedline":
+					old = &row[i]
+				case "diff-addedline":
+					new = &row[i]
+				case "diff-context":
+					context = true
+				}
+			}
+		}
+		rowKind := Equal
+		switch {
+		case old != nil && new != nil:
+			rowKind = Change
+		case old != nil:
+			rowKind = Delete
+		case new != nil:
+			rowKind = Insert
+		}
+		if rowKind != kind {
+			flush()
+			kind = rowKind
+		}
+		switch {
+		case len(numbers) == 2:
+			oldLine, newLine = numbers[0], numbers[1]
+		case context:
+			oldLine++
+			newLine++
+		}
+		if old != nil {
+			deleted = append(deleted, old.text)
+			oldLine++
+		}
+		if new != nil {
+			inserted = append(inserted, new.text)
+			newLine++
+		}
+	}
+	flush()
+	return hunks
+}
+
+func (page *PageClient) Diff(fromRev, toRev int64) (*Diff, error) {
+	return page.DiffContext(context.Background(), fromRev, toRev)
+}
+
+func (page *PageClient) DiffContext(ctx context.Context, fromRev, toRev int64) (*Diff, error) {
+	var r struct {
+		Compare *struct {
+			FromRevId int64  `json:"fromrevid"`
+			ToRevId   int64  `json:"torevid"`
+			Body      string `json:"body"`
+		} `json:"compare"`
+	}
+	err := query(ctx, page.wikipedia, map[string][]string{
+		"fromrev": {strconv.FormatInt(fromRev, 10)},
+		"torev":   {strconv.FormatInt(toRev, 10)},
+		"prop":    {"diff|ids"},
+		"format":  {"json"},
+		"action":  {"compare"},
+	}, &r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Compare == nil {
+		return nil, invalidResponse()
+	}
+	target := &PageClient{wikipedia: page.wikipedia, revision: r.Compare.ToRevId}
+	parse, err := target.parseRevision(ctx, map[string][]string{"prop": {"wikitext"}})
+	if err != nil {
+		return nil, err
+	}
+	return &Diff{
+		FromRev: r.Compare.FromRevId,
+		ToRev:   r.Compare.ToRevId,
+		Hunks:   parseDiffTable(r.Compare.Body, strings.Split(parse.Wikitext, "\n")),
+	}, nil
+}
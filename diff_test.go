@@ -0,0 +1,147 @@
+package wikipedia
+
+import "fmt"
+import "net/http"
+import "strings"
+import "testing"
+import "time"
+
+func TestDiffText(t *testing.T) {
+	t.Parallel()
+	old := "Intro.\n== History ==\nIt began in 1900.\nIt grew.\n== Legacy ==\nRemembered.\nForgotten."
+	new := "Intro.\n== History ==\nIt began in 1901.\nIt grew.\nIt shrank.\n== Legacy ==\nRemembered."
+	hunks := DiffText(old, new)
+	if len(hunks) != 3 {
+		t.Error(fmt.Sprintf("expected 3 hunks, got %+v", hunks))
+		return
+	}
+	change := hunks[0]
+	if change.Op != Change || change.Section != "History" || change.OldLine != 3 || change.NewLine != 3 {
+		t.Error(fmt.Sprintf("got wrong change hunk %+v", change))
+		return
+	}
+	words := change.Words
+	if len(words) != 3 || words[1].Op != Delete || words[1].Text != "1900." || words[2].Op != Insert || words[2].Text != "1901." {
+		t.Error(fmt.Sprintf("got wrong word diff %+v", words))
+		return
+	}
+	if hunks[1].Op != Insert || hunks[1].Section != "History" || hunks[1].New[0] != "It shrank." {
+		t.Error(fmt.Sprintf("got wrong insert hunk %+v", hunks[1]))
+		return
+	}
+	if hunks[2].Op != Delete || hunks[2].Section != "Legacy" || hunks[2].OldLine != 7 {
+		t.Error(fmt.Sprintf("got wrong delete hunk %+v", hunks[2]))
+		return
+	}
+	sections := (&Diff{Hunks: hunks}).BySection()
+	if len(sections) != 2 || sections[0].Section != "History" || len(sections[0].Hunks) != 2 || sections[1].Section != "Legacy" || len(sections[1].Hunks) != 1 {
+		t.Error(fmt.Sprintf("got wrong sections %+v", sections))
+		return
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	t.Parallel()
+	words := DiffWords("the quick brown fox", "the slow brown fox jumps")
+	expected := []WordDiff{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " brown fox"}, {Insert, " jumps"}}
+	if fmt.Sprint(words) != fmt.Sprint(expected) {
+		t.Error(fmt.Sprintf("got wrong word diff %+v", words))
+		return
+	}
+}
+
+func TestDiffTokens(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"abcabba", "cbabac", 5},
+		{"kitten", "sitting", 5},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abcdef", "abcdef", 0},
+	}
+	for _, test := range tests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		ops := diffTokens(a, b)
+		var old, new strings.Builder
+		edits := 0
+		for _, op := range ops {
+			if op.Op != Insert {
+				old.WriteString(op.Text)
+			}
+			if op.Op != Delete {
+				new.WriteString(op.Text)
+			}
+			if op.Op != Equal {
+				edits++
+			}
+		}
+		if old.String() != test.a || new.String() != test.b || edits != test.edits {
+			t.Error(fmt.Sprintf("got wrong edit script for %q -> %q: %v", test.a, test.b, ops))
+		}
+	}
+}
+
+func TestDiffTokensLarge(t *testing.T) {
+	t.Parallel()
+	a := make([]string, 50000)
+	b := make([]string, 50000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old %d", i)
+		b[i] = fmt.Sprintf("new %d", i)
+	}
+	start := time.Now()
+	ops := diffTokens(a, b)
+	if time.Since(start) > 5*time.Second {
+		t.Error(fmt.Sprintf("diff took %s", time.Since(start)))
+		return
+	}
+	if len(ops) != 100000 || ops[0].Op != Delete || ops[50000].Op != Insert {
+		t.Error("expected a whole-block delete and insert")
+		return
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("action") == "parse" {
+			if q.Get("oldid") != "11" {
+				t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+			}
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":11,"wikitext":"Intro.\n== History ==\nIt began in 1901.\nIt grew.\nIt shrank."}}`)
+			return
+		}
+		if q.Get("fromrev") != "10" || q.Get("torev") != "11" {
+			t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+		}
+		fmt.Fprint(rw, `{"compare":{"fromid":1,"fromrevid":10,"toid":1,"torevid":11,"body":`+
+			`"<tr><td colspan=\"2\" class=\"diff-lineno\">Line 2:</td><td colspan=\"2\" class=\"diff-lineno\">Line 2:</td></tr>`+
+			`<tr><td class=\"diff-marker\"></td><td class=\"diff-context diff-side-deleted\"><div>== History ==</div></td><td class=\"diff-marker\"></td><td class=\"diff-context diff-side-added\"><div>== History ==</div></td></tr>`+
+			`<tr><td class=\"diff-marker\" data-marker=\"−\"></td><td class=\"diff-deletedline diff-side-deleted\"><div>It began in <del class=\"diffchange diffchange-inline\">1900</del>.</div></td><td class=\"diff-marker\" data-marker=\"+\"></td><td class=\"diff-addedline diff-side-added\"><div>It began in <ins class=\"diffchange diffchange-inline\">1901</ins>.</div></td></tr>`+
+			`<tr><td class=\"diff-marker\"></td><td class=\"diff-context diff-side-deleted\"><div>It grew.</div></td><td class=\"diff-marker\"></td><td class=\"diff-context diff-side-added\"><div>It grew.</div></td></tr>`+
+			`<tr><td colspan=\"2\" class=\"diff-empty diff-side-deleted\"></td><td class=\"diff-marker\" data-marker=\"+\"></td><td class=\"diff-addedline diff-side-added\"><div>It shrank.</div></td></tr>"}}`)
+	})
+	diff, err := NewPage(w, "A").Diff(10, 11)
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting diff %s", err))
+		return
+	}
+	if diff.FromRev != 10 || diff.ToRev != 11 || len(diff.Hunks) != 2 {
+		t.Error(fmt.Sprintf("got wrong diff %+v", diff))
+		return
+	}
+	change := diff.Hunks[0]
+	if change.Op != Change || change.Section != "History" || change.NewLine != 3 || change.New[0] != "It began in 1901." {
+		t.Error(fmt.Sprintf("got wrong change hunk %+v", change))
+		return
+	}
+	insert := diff.Hunks[1]
+	if insert.Op != Insert || insert.Section != "History" || insert.NewLine != 5 || insert.New[0] != "It shrank." {
+		t.Error(fmt.Sprintf("got wrong insert hunk %+v", insert))
+		return
+	}
+}
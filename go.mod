@@ -0,0 +1,3 @@
+module github.com/seppo0010/wikipedia-go
+
+go 1.23
@@ -0,0 +1,116 @@
+package wikipedia
+
+import "encoding/xml"
+import "regexp"
+import "strings"
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "dd": true, "dt": true,
+	"tr": true, "table": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var cellElements = map[string]bool{
+	"td": true, "th": true,
+}
+
+var skippedElements = map[string]bool{
+	"style":  true,
+	"script": true,
+}
+
+var skippedClasses = []string{"mw-editsection", "reference"}
+
+var proseSkippedClasses = []string{"hatnote", "infobox", "navbox", "metadata"}
+
+var blankLines = regexp.MustCompile(`\n{2,}`)
+
+func newHtmlDecoder(s string) *xml.Decoder {
+	decoder := xml.NewDecoder(strings.NewReader(s))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func skipped(element xml.StartElement) bool {
+	if skippedElements[element.Name.Local] {
+		return true
+	}
+	return hasClass(element, skippedClasses)
+}
+
+func proseSkipped(element xml.StartElement) bool {
+	return skipped(element) || element.Name.Local == "table" || hasClass(element, proseSkippedClasses)
+}
+
+func hasClass(element xml.StartElement, names []string) bool {
+	classes := strings.Fields(attr(element, "class"))
+	for _, class := range names {
+		for _, c := range classes {
+			if c == class {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func htmlToText(s string) string {
+	return htmlText(s, skipped)
+}
+
+func htmlToProse(s string) string {
+	return htmlText(s, proseSkipped)
+}
+
+func htmlText(s string, skipped func(xml.StartElement) bool) string {
+	decoder := newHtmlDecoder(s)
+	var text strings.Builder
+	skip := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if skip > 0 || skipped(token) {
+				skip++
+				continue
+			}
+			if blockElements[token.Name.Local] {
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if blockElements[token.Name.Local] && token.Name.Local != "br" {
+				text.WriteString("\n")
+			}
+			if cellElements[token.Name.Local] {
+				text.WriteString("\t")
+			}
+		case xml.CharData:
+			if skip == 0 {
+				text.Write(token)
+			}
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(text.String(), "\u00a0", " "), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,80 @@
+package wikipedia
+
+import "context"
+import "strings"
+
+import "github.com/seppo0010/wikipedia-go/wikitext"
+
+type InfoboxValue struct {
+	Raw, Text string
+}
+
+type Infobox struct {
+	Name   string
+	Keys   []string
+	Values map[string]InfoboxValue
+}
+
+var infoboxNames = map[string]bool{
+	"automatic taxobox": true,
+	"chembox":           true,
+	"drugbox":           true,
+	"geobox":            true,
+	"speciesbox":        true,
+	"taxobox":           true,
+}
+
+func isInfobox(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "infobox") || strings.HasSuffix(name, " infobox") || infoboxNames[name]
+}
+
+func newInfobox(template *wikitext.Template) Infobox {
+	infobox := Infobox{
+		Name:   template.Name,
+		Keys:   make([]string, 0, len(template.Params)),
+		Values: make(map[string]InfoboxValue),
+	}
+	for _, param := range template.Params {
+		if _, ok := infobox.Values[param.Name]; !ok {
+			infobox.Keys = append(infobox.Keys, param.Name)
+		}
+		infobox.Values[param.Name] = InfoboxValue{Raw: param.Raw, Text: templateText(param.Value)}
+	}
+	return infobox
+}
+
+func findInfoboxes(doc *wikitext.Document) []Infobox {
+	infoboxes := make([]Infobox, 0)
+	wikitext.Inspect(doc.Nodes, func(node wikitext.Node) bool {
+		if template, ok := node.(*wikitext.Template); ok && isInfobox(template.Name) {
+			infoboxes = append(infoboxes, newInfobox(template))
+		}
+		return true
+	})
+	return infoboxes
+}
+
+func (page *PageClient) Infoboxes() ([]Infobox, error) {
+	return page.InfoboxesContext(context.Background())
+}
+
+func (page *PageClient) InfoboxesContext(ctx context.Context) ([]Infobox, error) {
+	doc, err := page.ParseWikitextContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findInfoboxes(doc), nil
+}
+
+func (page *PageClient) Infobox() (*Infobox, error) {
+	return page.InfoboxContext(context.Background())
+}
+
+func (page *PageClient) InfoboxContext(ctx context.Context) (*Infobox, error) {
+	infoboxes, err := page.InfoboxesContext(ctx)
+	if err != nil || len(infoboxes) == 0 {
+		return nil, err
+	}
+	return &infoboxes[0], nil
+}
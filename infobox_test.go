@@ -0,0 +1,72 @@
+package wikipedia
+
+import "encoding/json"
+import "fmt"
+import "net/http"
+import "testing"
+
+const infoboxWikitext = `{{Infobox settlement
+| name = Springfield <!-- not Shelbyville -->
+| population_total = {{formatnum:30720}}
+| area_total_km2 = {{convert|10|km2|sqmi}}
+| established_date = {{start date|1796|3|5}}
+| leader_name = [[Jane Doe|Mayor Doe]]<ref>{{cite web|url=http://example.com}}</ref>
+| website = {{URL|example.com}}
+| twin = {{ubl|[[Shelbyville]]|Capital City}}
+| module = {{Infobox mapframe|zoom=10}}
+}}
+'''Springfield''' is a city.
+{{Infobox person|name=Jebediah}}`
+
+func TestInfoboxes(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		content, _ := json.Marshal(infoboxWikitext)
+		fmt.Fprintf(rw, `{"query":{"pages":[{"pageid":1,"title":"Springfield","revisions":[{"revid":42,"slots":{"main":{"contentmodel":"wikitext","content":%s}}}]}]}}`, content)
+	})
+	page := NewPage(w, "Springfield")
+	infoboxes, err := page.Infoboxes()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting infoboxes %s", err))
+		return
+	}
+	if len(infoboxes) != 3 || infoboxes[1].Name != "Infobox mapframe" || infoboxes[2].Values["name"].Text != "Jebediah" {
+		t.Error(fmt.Sprintf("got wrong infoboxes %+v", infoboxes))
+		return
+	}
+	infobox, err := page.Infobox()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting infobox %s", err))
+		return
+	}
+	if infobox.Name != "Infobox settlement" || len(infobox.Keys) != 8 || infobox.Keys[1] != "population_total" {
+		t.Error(fmt.Sprintf("got wrong infobox %+v", infobox))
+		return
+	}
+	expected := map[string]InfoboxValue{
+		"name":             {"Springfield <!-- not Shelbyville -->", "Springfield"},
+		"population_total": {"{{formatnum:30720}}", "30720"},
+		"area_total_km2":   {"{{convert|10|km2|sqmi}}", "10 km2"},
+		"established_date": {"{{start date|1796|3|5}}", "1796-03-05"},
+		"leader_name":      {"[[Jane Doe|Mayor Doe]]<ref>{{cite web|url=http://example.com}}</ref>", "Mayor Doe"},
+		"website":          {"{{URL|example.com}}", "example.com"},
+		"twin":             {"{{ubl|[[Shelbyville]]|Capital City}}", "Shelbyville\nCapital City"},
+	}
+	for key, value := range expected {
+		if infobox.Values[key] != value {
+			t.Error(fmt.Sprintf("got wrong value for %s: %+v", key, infobox.Values[key]))
+		}
+	}
+}
+
+func TestNoInfobox(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":42,"slots":{"main":{"contentmodel":"wikitext","content":"Just text."}}}]}]}}`)
+	})
+	infobox, err := NewPage(w, "A").Infobox()
+	if err != nil || infobox != nil {
+		t.Error(fmt.Sprintf("expected no infobox, got %+v %v", infobox, err))
+		return
+	}
+}
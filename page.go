@@ -1,26 +1,93 @@
 package wikipedia
 
-import "errors"
+import "context"
 import "fmt"
-import "strings"
+import "iter"
+import "strconv"
+import "sync"
+import "time"
+
+import "github.com/seppo0010/wikipedia-go/wikitext"
 
 type Page interface {
 	Id() (pageId string, err error)
+	IdContext(ctx context.Context) (pageId string, err error)
 	Title() (pageTitle string, err error)
+	TitleContext(ctx context.Context) (pageTitle string, err error)
 	Content() (content string, err error)
+	ContentContext(ctx context.Context) (content string, err error)
 	HtmlContent() (content string, err error)
+	HtmlContentContext(ctx context.Context) (content string, err error)
 	Summary() (summary string, err error)
+	SummaryContext(ctx context.Context) (summary string, err error)
 	Images() <-chan ImageRequest
+	ImagesContext(ctx context.Context) <-chan ImageRequest
+	ImagesSeq() iter.Seq2[Image, error]
+	ImagesSeqContext(ctx context.Context) iter.Seq2[Image, error]
+	ResumeImages(cursor *Cursor) iter.Seq2[Image, error]
+	ResumeImagesContext(ctx context.Context, cursor *Cursor) iter.Seq2[Image, error]
 	Extlinks() <-chan ReferenceRequest
+	ExtlinksContext(ctx context.Context) <-chan ReferenceRequest
+	ExtlinksSeq() iter.Seq2[Reference, error]
+	ExtlinksSeqContext(ctx context.Context) iter.Seq2[Reference, error]
+	ResumeExtlinks(cursor *Cursor) iter.Seq2[Reference, error]
+	ResumeExtlinksContext(ctx context.Context, cursor *Cursor) iter.Seq2[Reference, error]
 	Links() <-chan LinkRequest
+	LinksContext(ctx context.Context) <-chan LinkRequest
+	LinksSeq() iter.Seq2[Link, error]
+	LinksSeqContext(ctx context.Context) iter.Seq2[Link, error]
+	ResumeLinks(cursor *Cursor) iter.Seq2[Link, error]
+	ResumeLinksContext(ctx context.Context, cursor *Cursor) iter.Seq2[Link, error]
 	Categories() <-chan CategoryRequest
+	CategoriesContext(ctx context.Context) <-chan CategoryRequest
+	CategoriesSeq() iter.Seq2[Category, error]
+	CategoriesSeqContext(ctx context.Context) iter.Seq2[Category, error]
+	ResumeCategories(cursor *Cursor) iter.Seq2[Category, error]
+	ResumeCategoriesContext(ctx context.Context, cursor *Cursor) iter.Seq2[Category, error]
+	Wikitext() (*Wikitext, error)
+	WikitextContext(ctx context.Context) (*Wikitext, error)
+	SectionWikitext(index int) (*Wikitext, error)
+	SectionWikitextContext(ctx context.Context, index int) (*Wikitext, error)
+	ParseWikitext() (*wikitext.Document, error)
+	ParseWikitextContext(ctx context.Context) (*wikitext.Document, error)
+	Infobox() (*Infobox, error)
+	InfoboxContext(ctx context.Context) (*Infobox, error)
+	Infoboxes() ([]Infobox, error)
+	InfoboxesContext(ctx context.Context) ([]Infobox, error)
+	Tables() ([]Table, error)
+	TablesContext(ctx context.Context) ([]Table, error)
+	References() ([]Citation, error)
+	ReferencesContext(ctx context.Context) ([]Citation, error)
 	Sections() (titles []string, err error)
+	SectionsContext(ctx context.Context) (titles []string, err error)
+	SectionTree() (*SectionTree, error)
+	SectionTreeContext(ctx context.Context) (*SectionTree, error)
 	SectionContent(title string) (sectionContent string, err error)
+	SectionContentContext(ctx context.Context, title string) (sectionContent string, err error)
+	ReadSection(section *Section, opts SectionOptions) (string, error)
+	ReadSectionContext(ctx context.Context, section *Section, opts SectionOptions) (string, error)
+	Redirects() (redirects []Redirect, err error)
+	RedirectsContext(ctx context.Context) (redirects []Redirect, err error)
+	IsDisambiguation() (bool, error)
+	IsDisambiguationContext(ctx context.Context) (bool, error)
+	DisambiguationOptions() (titles []string, err error)
+	DisambiguationOptionsContext(ctx context.Context) (titles []string, err error)
+	Revisions(opts RevisionOptions) iter.Seq2[Revision, error]
+	RevisionsContext(ctx context.Context, opts RevisionOptions) iter.Seq2[Revision, error]
+	Diff(fromRev, toRev int64) (*Diff, error)
+	DiffContext(ctx context.Context, fromRev, toRev int64) (*Diff, error)
+	AsOf(t time.Time) (Page, error)
+	AsOfContext(ctx context.Context, t time.Time) (Page, error)
+	Load(opts LoadOptions) (snapshot *PageSnapshot, err error)
+	LoadContext(ctx context.Context, opts LoadOptions) (snapshot *PageSnapshot, err error)
 }
 
 type PageClient struct {
 	wikipedia Wikipedia
 	title, id string
+	revision  int64
+	mu        sync.Mutex
+	snapshot  *PageSnapshot
 }
 
 type Image struct {
@@ -92,7 +159,17 @@ func NewPageFromId(wikipedia Wikipedia, id string) *PageClient {
 	}
 }
 
+func NewPageAtRevision(wikipedia Wikipedia, revid int64) *PageClient {
+	return &PageClient{
+		revision:  revid,
+		wikipedia: wikipedia,
+	}
+}
+
 func (page *PageClient) queryParam() (string, string) {
+	if page.revision != 0 {
+		return "revids", strconv.FormatInt(page.revision, 10)
+	}
 	if page.title != "" {
 		return "titles", page.title
 	}
@@ -102,227 +179,373 @@ func (page *PageClient) queryParam() (string, string) {
 	panic("Page must have a title or an id")
 }
 
-func (page *PageClient) redirect(r interface{}) (title string, redirect bool) {
-	if v, ok := r.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if redirects, ok := query["redirects"].([]interface{}); ok {
-				if len(redirects) > 0 {
-					if to, ok := redirects[0].(string); ok {
-						if to != "" {
-							redirect = true
-							title = to
-						}
-					}
-				}
+func (page *PageClient) parseParam() (string, string) {
+	if page.revision != 0 {
+		return "oldid", strconv.FormatInt(page.revision, 10)
+	}
+	if page.title != "" {
+		return "page", page.title
+	}
+	if page.id != "" {
+		return "pageid", page.id
+	}
+	panic("Page must have a title or an id")
+}
+
+func (page *PageClient) parseRevision(ctx context.Context, params map[string][]string) (*apiParse, error) {
+	params["format"] = []string{"json"}
+	params["action"] = []string{"parse"}
+	params["oldid"] = []string{strconv.FormatInt(page.revision, 10)}
+	params["disableeditsection"] = []string{"1"}
+	var r apiResponse
+	err := query(ctx, page.wikipedia, params, &r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Parse == nil {
+		return nil, invalidResponse()
+	}
+	return r.Parse, nil
+}
+
+type Redirect struct {
+	From, To, Fragment string
+	Normalized         bool
+}
+
+func (page *PageClient) withRedirects(params map[string][]string) map[string][]string {
+	if page.wikipedia.FollowRedirects() {
+		params["redirects"] = []string{""}
+	}
+	return params
+}
+
+func parseRedirectList(list []apiRedirect, normalized bool) []Redirect {
+	redirects := make([]Redirect, 0, len(list))
+	for _, el := range list {
+		redirects = append(redirects, Redirect{From: el.From, To: el.To, Fragment: el.ToFragment, Normalized: normalized})
+	}
+	return redirects
+}
+
+func resolveRedirects(query *apiQuery, title string) ([]Redirect, error) {
+	chain := make([]Redirect, 0)
+	if query == nil {
+		return chain, nil
+	}
+	redirects := parseRedirectList(query.Redirects, false)
+	if title == "" {
+		for _, redirect := range redirects {
+			isTarget := false
+			for _, other := range redirects {
+				isTarget = isTarget || other.To == redirect.From
+			}
+			if !isTarget {
+				title = redirect.From
+				break
+			}
+		}
+		if title == "" && len(redirects) > 0 {
+			return nil, newError(RedirectLoopError, fmt.Errorf("redirect loop at %q", redirects[0].From))
+		}
+	}
+	for _, normalized := range parseRedirectList(query.Normalized, true) {
+		if normalized.From == title {
+			chain = append(chain, normalized)
+			title = normalized.To
+			break
+		}
+	}
+	visited := map[string]bool{title: true}
+	for {
+		found := false
+		for _, redirect := range redirects {
+			if redirect.From == title {
+				chain = append(chain, redirect)
+				title = redirect.To
+				found = true
+				break
 			}
 		}
+		if !found {
+			return chain, nil
+		}
+		if visited[title] {
+			return nil, newError(RedirectLoopError, fmt.Errorf("redirect loop at %q", title))
+		}
+		visited[title] = true
 	}
-	return
+}
+
+func (page *PageClient) Redirects() ([]Redirect, error) {
+	return page.RedirectsContext(context.Background())
+}
+
+func (page *PageClient) RedirectsContext(ctx context.Context) ([]Redirect, error) {
+	k, v := page.queryParam()
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"format": {"json"},
+		"action": {"query"},
+		k:        {v},
+	}), &r)
+	if err != nil {
+		return nil, err
+	}
+	return resolveRedirects(r.Query, page.title)
 }
 
 func (page *PageClient) Id() (string, error) {
+	return page.IdContext(context.Background())
+}
+
+func (page *PageClient) IdContext(ctx context.Context) (string, error) {
+	if snapshot := page.cachedSnapshot(); snapshot != nil {
+		return snapshot.Id, nil
+	}
 	if page.id != "" {
 		return page.id, nil
 	}
 	k, v := page.queryParam()
-	var f interface{}
-	err := query(page.wikipedia, map[string][]string{
-		"prop":      {"info|pageprops"},
-		"inprop":    {"url"},
-		"ppprop":    {"disambiguation"},
-		"redirects": {""},
-		"format":    {"json"},
-		"action":    {"query"},
-		k:           {v},
-	}, &f)
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":   {"info|pageprops"},
+		"inprop": {"url"},
+		"ppprop": {"disambiguation"},
+		"format": {"json"},
+		"action": {"query"},
+		k:        {v},
+	}), &r)
 	if err != nil {
 		return "", err
 	}
-	if title, redirect := page.redirect(f); redirect {
-		return NewPage(page.wikipedia, title).Id()
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return "", err
 	}
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for pageString := range pages {
-					return pageString, nil
-				}
-			}
-		}
+	p, err := r.firstPage()
+	if err != nil {
+		return "", err
 	}
-	return "", newError(ResponseError, errors.New("invalid json response"))
+	return p.id(), nil
 }
 
 func (page *PageClient) Title() (string, error) {
+	return page.TitleContext(context.Background())
+}
+
+func (page *PageClient) TitleContext(ctx context.Context) (string, error) {
+	if snapshot := page.cachedSnapshot(); snapshot != nil {
+		return snapshot.Title, nil
+	}
 	if page.title != "" {
 		return page.title, nil
 	}
 	k, v := page.queryParam()
-	var f interface{}
-	err := query(page.wikipedia, map[string][]string{
-		"prop":      {"info|pageprops"},
-		"inprop":    {"url"},
-		"ppprop":    {"disambiguation"},
-		"redirects": {""},
-		"format":    {"json"},
-		"action":    {"query"},
-		k:           {v},
-	}, &f)
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":   {"info|pageprops"},
+		"inprop": {"url"},
+		"ppprop": {"disambiguation"},
+		"format": {"json"},
+		"action": {"query"},
+		k:        {v},
+	}), &r)
 	if err != nil {
 		return "", err
 	}
-	if title, redirect := page.redirect(f); redirect {
-		return NewPage(page.wikipedia, title).Title()
-	}
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if pageObject, ok := page.(map[string]interface{}); ok {
-						if pageTitle, ok := pageObject["title"].(string); ok {
-							return pageTitle, nil
-						}
-					}
-				}
-			}
-		}
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return "", err
 	}
-	return "", newError(ResponseError, errors.New("invalid json response"))
+	p, err := r.firstPage()
+	if err != nil {
+		return "", err
+	}
+	return p.Title, nil
 }
 
-func getFirstPage(f interface{}) (map[string]interface{}, bool) {
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if val, ok := page.(map[string]interface{}); ok {
-						return val, true
-					}
-				}
-			}
+func (page *PageClient) IsDisambiguation() (bool, error) {
+	return page.IsDisambiguationContext(context.Background())
+}
+
+func (page *PageClient) IsDisambiguationContext(ctx context.Context) (bool, error) {
+	if snapshot := page.cachedSnapshot(); snapshot != nil {
+		return snapshot.Disambiguation, nil
+	}
+	k, v := page.queryParam()
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":   {"pageprops"},
+		"ppprop": {"disambiguation"},
+		"format": {"json"},
+		"action": {"query"},
+		k:        {v},
+	}), &r)
+	if err != nil {
+		return false, err
+	}
+	p, err := r.firstPage()
+	if err != nil {
+		return false, err
+	}
+	return p.isDisambiguation(), nil
+}
+
+func (page *PageClient) DisambiguationOptions() ([]string, error) {
+	return page.DisambiguationOptionsContext(context.Background())
+}
+
+func (page *PageClient) DisambiguationOptionsContext(ctx context.Context) ([]string, error) {
+	k, v := page.queryParam()
+	titles := make([]string, 0)
+	params := map[string][]string{"continue": {""}}
+	first := true
+	for {
+		for k, v := range map[string][]string{
+			"prop":        {"pageprops|links"},
+			"ppprop":      {"disambiguation"},
+			"pllimit":     {"max"},
+			"plnamespace": {"0"},
+			"format":      {"json"},
+			"action":      {"query"},
+			k:             {v},
+		} {
+			params[k] = v
+		}
+		var r apiResponse
+		err := query(ctx, page.wikipedia, page.withRedirects(params), &r)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := resolveRedirects(r.Query, page.title); err != nil {
+			return nil, err
+		}
+		p, err := r.firstPage()
+		if err != nil {
+			return nil, err
+		}
+		if first && !p.isDisambiguation() {
+			return nil, nil
+		}
+		first = false
+		for _, link := range p.Links {
+			titles = append(titles, link.Title)
+		}
+		params = parseCont(&r)
+		if len(params) == 0 {
+			return titles, nil
 		}
 	}
-	return nil, false
 }
 
 func (page *PageClient) Content() (string, error) {
+	return page.ContentContext(context.Background())
+}
+
+func (page *PageClient) ContentContext(ctx context.Context) (string, error) {
+	if page.revision != 0 {
+		parse, err := page.parseRevision(ctx, map[string][]string{"prop": {"text"}})
+		if err != nil {
+			return "", err
+		}
+		return htmlToProse(parse.Text), nil
+	}
+	if snapshot := page.cachedSnapshot(); snapshot != nil && !snapshot.summaryOnly {
+		return snapshot.Extract, nil
+	}
 	k, v := page.queryParam()
-	var f interface{}
-	err := query(page.wikipedia, map[string][]string{
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
 		"prop":        {"extracts|revisions"},
 		"explaintext": {""},
 		"rvprop":      {"ids"},
-		"redirects":   {""},
 		"format":      {"json"},
 		"action":      {"query"},
 		k:             {v},
-	}, &f)
+	}), &r)
 	if err != nil {
 		return "", err
 	}
-	if title, redirect := page.redirect(f); redirect {
-		return NewPage(page.wikipedia, title).Content()
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return "", err
 	}
-	if v, ok := getFirstPage(f); ok {
-		if extract, ok := v["extract"].(string); ok {
-			return extract, nil
-		}
+	p, err := r.firstPage()
+	if err != nil {
+		return "", err
 	}
-	return "", newError(ResponseError, errors.New("invalid json response"))
+	return p.Extract, nil
 }
 
 func (page *PageClient) HtmlContent() (string, error) {
-	k, v := page.queryParam()
-	var f interface{}
-	err := query(page.wikipedia, map[string][]string{
-		"prop":        {"revisions"},
-		"explaintext": {""},
-		"rvprop":      {"content"},
-		"rvlimit":     {"1"},
-		"rvparse":     {""},
-		"redirects":   {""},
-		"format":      {"json"},
-		"action":      {"query"},
-		k:             {v},
-	}, &f)
+	return page.HtmlContentContext(context.Background())
+}
+
+func (page *PageClient) HtmlContentContext(ctx context.Context) (string, error) {
+	k, v := page.parseParam()
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":   {"text"},
+		"format": {"json"},
+		"action": {"parse"},
+		k:        {v},
+	}), &r)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
-	if title, redirect := page.redirect(f); redirect {
-		return NewPage(page.wikipedia, title).HtmlContent()
+	if r.Parse == nil {
+		return "", invalidResponse()
 	}
-	if v, ok := getFirstPage(f); ok {
-		if revisions, ok := v["revisions"].([]interface{}); ok {
-			for _, revisionInterface := range revisions {
-				if revision, ok := revisionInterface.(map[string]interface{}); ok {
-					if html, ok := revision["*"].(string); ok {
-						return html, nil
-					}
-				}
-			}
-		}
-	}
-	return "", newError(ResponseError, errors.New("invalid json response"))
+	return r.Parse.Text, nil
 }
 
 func (page *PageClient) Summary() (string, error) {
+	return page.SummaryContext(context.Background())
+}
+
+func (page *PageClient) SummaryContext(ctx context.Context) (string, error) {
+	if page.revision != 0 {
+		parse, err := page.parseRevision(ctx, map[string][]string{"prop": {"text"}, "section": {"0"}})
+		if err != nil {
+			return "", err
+		}
+		return htmlToProse(parse.Text), nil
+	}
+	if snapshot := page.cachedSnapshot(); snapshot != nil {
+		return snapshot.Intro, nil
+	}
 	k, v := page.queryParam()
-	var f interface{}
-	err := query(page.wikipedia, map[string][]string{
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
 		"prop":        {"extracts"},
 		"explaintext": {""},
 		"exintro":     {""},
-		"redirects":   {""},
 		"format":      {"json"},
 		"action":      {"query"},
 		k:             {v},
-	}, &f)
+	}), &r)
 	if err != nil {
 		return "", err
 	}
-	if title, redirect := page.redirect(f); redirect {
-		return NewPage(page.wikipedia, title).Summary()
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return "", err
 	}
-	if v, ok := getFirstPage(f); ok {
-		if extract, ok := v["extract"].(string); ok {
-			return extract, nil
-		}
+	p, err := r.firstPage()
+	if err != nil {
+		return "", err
 	}
-	return "", newError(ResponseError, errors.New("invalid json response"))
+	return p.Extract, nil
 }
 
-func parseCont(q interface{}) (map[string][]string, error) {
+func parseCont(r *apiResponse) map[string][]string {
 	params := make(map[string][]string)
-	if q2, ok := q.(map[string]interface{}); ok {
-		if cont, ok := q2["continue"].(map[string]interface{}); ok {
-			for k, vUntyped := range cont {
-				switch v := vUntyped.(type) {
-				case int:
-					params[k] = []string{fmt.Sprintf("%d", v)}
-				case nil:
-					params[k] = []string{""}
-				case bool:
-					if v {
-						params[k] = []string{"1"}
-					} else {
-						params[k] = []string{"0"}
-					}
-				case float64:
-					params[k] = []string{fmt.Sprintf("%f", v)}
-				case string:
-					params[k] = []string{v}
-				default:
-					return nil, errors.New("invalid continue parameter")
-				}
-			}
-		}
+	for k, v := range r.Continue {
+		params[k] = []string{string(v)}
 	}
-	return params, nil
+	return params
 }
 
-func (page *PageClient) requestImages(params map[string][]string) (*ImagesRequest, error) {
+func (page *PageClient) requestImages(ctx context.Context, params map[string][]string) (*ImagesRequest, error) {
 	k, v := page.queryParam()
-	var f interface{}
+	var r apiResponse
 	if len(params) == 0 {
 		params["continue"] = []string{""}
 	}
@@ -337,70 +560,96 @@ func (page *PageClient) requestImages(params map[string][]string) (*ImagesReques
 	} {
 		params[k] = v
 	}
-	err := query(page.wikipedia, params, &f)
+	err := query(ctx, page.wikipedia, params, &r)
 	if err != nil {
 		return nil, err
 	}
 	imagesRequest := new(ImagesRequest)
-	imagesRequest.cont, err = parseCont(f)
-	if err != nil {
-		return nil, err
-	}
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if v, ok := page.(map[string]interface{}); ok {
-						title, _ := v["title"].(string)
-						url := ""
-						descriptionUrl := ""
-						if imageinfo, ok := v["imageinfo"].([]interface{}); ok {
-							if len(imageinfo) > 0 {
-								if info, ok := imageinfo[0].(map[string]interface{}); ok {
-									url, _ = info["url"].(string)
-									descriptionUrl, _ = info["descriptionurl"].(string)
-								}
-							}
-						}
-						imagesRequest.images = append(imagesRequest.images, Image{Title: title, Url: url, DescriptionUrl: descriptionUrl})
-					}
-				}
-			}
+	imagesRequest.cont = parseCont(&r)
+	for _, p := range r.pages() {
+		image := Image{Title: p.Title}
+		if len(p.ImageInfo) > 0 {
+			image.Url = p.ImageInfo[0].Url
+			image.DescriptionUrl = p.ImageInfo[0].DescriptionUrl
 		}
+		imagesRequest.images = append(imagesRequest.images, image)
 	}
 	if len(imagesRequest.images) == 0 {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+		return nil, invalidResponse()
 	}
 	return imagesRequest, nil
 
 }
 
 func (page *PageClient) Images() <-chan ImageRequest {
+	return page.ImagesContext(context.Background())
+}
+
+func (page *PageClient) ImagesContext(ctx context.Context) <-chan ImageRequest {
 	ch := make(chan ImageRequest)
 	go func() {
 		defer close(ch)
-		cont := make(map[string][]string)
-		for {
-			imagesRequest, err := page.requestImages(cont)
-			if err != nil {
-				ch <- ImageRequest{Err: err}
+		for image, err := range page.ImagesSeqContext(ctx) {
+			select {
+			case ch <- ImageRequest{Image: image, Err: err}:
+			case <-ctx.Done():
 				return
 			}
-			for _, image := range imagesRequest.images {
-				ch <- ImageRequest{Image: image}
-			}
-			cont = imagesRequest.cont
-			if len(cont) == 0 {
-				break
-			}
 		}
 	}()
 	return ch
 }
 
-func (page *PageClient) requestExtlinks(params map[string][]string) (*ReferencesRequest, error) {
+func (page *PageClient) ImagesSeq() iter.Seq2[Image, error] {
+	return page.ImagesSeqContext(context.Background())
+}
+
+func (page *PageClient) ImagesSeqContext(ctx context.Context) iter.Seq2[Image, error] {
+	return func(yield func(Image, error) bool) {
+		if snapshot := page.cachedSnapshot(); snapshot != nil && snapshot.withImages {
+			for _, image := range snapshot.Images {
+				if !yield(image, nil) {
+					return
+				}
+			}
+			return
+		}
+		for image, err := range page.ResumeImagesContext(ctx, &Cursor{}) {
+			if !yield(image, err) {
+				return
+			}
+		}
+	}
+}
+
+func (page *PageClient) ResumeImages(cursor *Cursor) iter.Seq2[Image, error] {
+	return page.ResumeImagesContext(context.Background(), cursor)
+}
+
+func (page *PageClient) ResumeImagesContext(ctx context.Context, cursor *Cursor) iter.Seq2[Image, error] {
+	return resume(ctx, cursor, func(ctx context.Context, params map[string][]string) ([]Image, map[string][]string, error) {
+		imagesRequest, err := page.requestImages(ctx, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return imagesRequest.images, imagesRequest.cont, nil
+	})
+}
+
+func (page *PageClient) requestExtlinks(ctx context.Context, params map[string][]string) (*ReferencesRequest, error) {
+	if page.revision != 0 {
+		parse, err := page.parseRevision(ctx, map[string][]string{"prop": {"externallinks"}})
+		if err != nil {
+			return nil, err
+		}
+		referencesRequest := new(ReferencesRequest)
+		for _, url := range parse.ExternalLinks {
+			referencesRequest.references = append(referencesRequest.references, Reference{Url: url})
+		}
+		return referencesRequest, nil
+	}
 	k, v := page.queryParam()
-	var f interface{}
+	var r apiResponse
 	if len(params) == 0 {
 		params["continue"] = []string{""}
 	}
@@ -413,68 +662,85 @@ func (page *PageClient) requestExtlinks(params map[string][]string) (*References
 	} {
 		params[k] = v
 	}
-	err := query(page.wikipedia, params, &f)
+	err := query(ctx, page.wikipedia, params, &r)
 	if err != nil {
 		return nil, err
 	}
 	referencesRequest := new(ReferencesRequest)
-	referencesRequest.cont, err = parseCont(f)
-	if err != nil {
-		return nil, err
-	}
+	referencesRequest.cont = parseCont(&r)
 
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if v, ok := page.(map[string]interface{}); ok {
-						if extlinks, ok := v["extlinks"].([]interface{}); ok {
-							for _, elI := range extlinks {
-								if el, ok := elI.(map[string]interface{}); ok {
-									if url, ok := el["*"].(string); ok {
-										referencesRequest.references = append(referencesRequest.references, Reference{Url: url})
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	for _, p := range r.pages() {
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		for _, el := range p.ExtLinks {
+			referencesRequest.references = append(referencesRequest.references, Reference{Url: el.Url})
 		}
 	}
 	if len(referencesRequest.references) == 0 {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+		return nil, invalidResponse()
 	}
 	return referencesRequest, nil
 
 }
 
 func (page *PageClient) Extlinks() <-chan ReferenceRequest {
+	return page.ExtlinksContext(context.Background())
+}
+
+func (page *PageClient) ExtlinksContext(ctx context.Context) <-chan ReferenceRequest {
 	ch := make(chan ReferenceRequest)
 	go func() {
 		defer close(ch)
-		cont := make(map[string][]string)
-		for {
-			referencesRequest, err := page.requestExtlinks(cont)
-			if err != nil {
-				ch <- ReferenceRequest{Err: err}
+		for reference, err := range page.ExtlinksSeqContext(ctx) {
+			select {
+			case ch <- ReferenceRequest{Reference: reference, Err: err}:
+			case <-ctx.Done():
 				return
 			}
-			for _, reference := range referencesRequest.references {
-				ch <- ReferenceRequest{Reference: reference}
-			}
-			cont = referencesRequest.cont
-			if len(cont) == 0 {
-				break
-			}
 		}
 	}()
 	return ch
 }
 
-func (page *PageClient) requestLinks(params map[string][]string) (*LinksRequest, error) {
+func (page *PageClient) ExtlinksSeq() iter.Seq2[Reference, error] {
+	return page.ExtlinksSeqContext(context.Background())
+}
+
+func (page *PageClient) ExtlinksSeqContext(ctx context.Context) iter.Seq2[Reference, error] {
+	return page.ResumeExtlinksContext(ctx, &Cursor{})
+}
+
+func (page *PageClient) ResumeExtlinks(cursor *Cursor) iter.Seq2[Reference, error] {
+	return page.ResumeExtlinksContext(context.Background(), cursor)
+}
+
+func (page *PageClient) ResumeExtlinksContext(ctx context.Context, cursor *Cursor) iter.Seq2[Reference, error] {
+	return resume(ctx, cursor, func(ctx context.Context, params map[string][]string) ([]Reference, map[string][]string, error) {
+		referencesRequest, err := page.requestExtlinks(ctx, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return referencesRequest.references, referencesRequest.cont, nil
+	})
+}
+
+func (page *PageClient) requestLinks(ctx context.Context, params map[string][]string) (*LinksRequest, error) {
+	if page.revision != 0 {
+		parse, err := page.parseRevision(ctx, map[string][]string{"prop": {"links"}})
+		if err != nil {
+			return nil, err
+		}
+		linksRequest := new(LinksRequest)
+		for _, link := range parse.Links {
+			if link.Ns == 0 {
+				linksRequest.links = append(linksRequest.links, Link{Title: link.Title})
+			}
+		}
+		return linksRequest, nil
+	}
 	k, v := page.queryParam()
-	var f interface{}
+	var r apiResponse
 	if len(params) == 0 {
 		params["continue"] = []string{""}
 	}
@@ -488,68 +754,72 @@ func (page *PageClient) requestLinks(params map[string][]string) (*LinksRequest,
 	} {
 		params[k] = v
 	}
-	err := query(page.wikipedia, params, &f)
+	err := query(ctx, page.wikipedia, params, &r)
 	if err != nil {
 		return nil, err
 	}
 	linksRequest := new(LinksRequest)
-	linksRequest.cont, err = parseCont(f)
-	if err != nil {
-		return nil, err
-	}
+	linksRequest.cont = parseCont(&r)
 
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if v, ok := page.(map[string]interface{}); ok {
-						if links, ok := v["links"].([]interface{}); ok {
-							for _, elI := range links {
-								if el, ok := elI.(map[string]interface{}); ok {
-									if title, ok := el["title"].(string); ok {
-										linksRequest.links = append(linksRequest.links, Link{Title: title})
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	for _, p := range r.pages() {
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		for _, el := range p.Links {
+			linksRequest.links = append(linksRequest.links, Link{Title: el.Title})
 		}
 	}
 	if len(linksRequest.links) == 0 {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+		return nil, invalidResponse()
 	}
 	return linksRequest, nil
 
 }
 
 func (page *PageClient) Links() <-chan LinkRequest {
+	return page.LinksContext(context.Background())
+}
+
+func (page *PageClient) LinksContext(ctx context.Context) <-chan LinkRequest {
 	ch := make(chan LinkRequest)
 	go func() {
 		defer close(ch)
-		cont := make(map[string][]string)
-		for {
-			linksRequest, err := page.requestLinks(cont)
-			if err != nil {
-				ch <- LinkRequest{Err: err}
+		for link, err := range page.LinksSeqContext(ctx) {
+			select {
+			case ch <- LinkRequest{Link: link, Err: err}:
+			case <-ctx.Done():
 				return
 			}
-			for _, link := range linksRequest.links {
-				ch <- LinkRequest{Link: link}
-			}
-			cont = linksRequest.cont
-			if len(cont) == 0 {
-				break
-			}
 		}
 	}()
 	return ch
 }
 
-func (page *PageClient) requestCategories(params map[string][]string) (*CategoriesRequest, error) {
+func (page *PageClient) LinksSeq() iter.Seq2[Link, error] {
+	return page.LinksSeqContext(context.Background())
+}
+
+func (page *PageClient) LinksSeqContext(ctx context.Context) iter.Seq2[Link, error] {
+	return page.ResumeLinksContext(ctx, &Cursor{})
+}
+
+func (page *PageClient) ResumeLinks(cursor *Cursor) iter.Seq2[Link, error] {
+	return page.ResumeLinksContext(context.Background(), cursor)
+}
+
+func (page *PageClient) ResumeLinksContext(ctx context.Context, cursor *Cursor) iter.Seq2[Link, error] {
+	return resume(ctx, cursor, func(ctx context.Context, params map[string][]string) ([]Link, map[string][]string, error) {
+		linksRequest, err := page.requestLinks(ctx, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return linksRequest.links, linksRequest.cont, nil
+	})
+}
+
+func (page *PageClient) requestCategories(ctx context.Context, params map[string][]string) (*CategoriesRequest, error) {
 	k, v := page.queryParam()
-	var f interface{}
+	var r apiResponse
 	if len(params) == 0 {
 		params["continue"] = []string{""}
 	}
@@ -562,117 +832,114 @@ func (page *PageClient) requestCategories(params map[string][]string) (*Categori
 	} {
 		params[k] = v
 	}
-	err := query(page.wikipedia, params, &f)
+	err := query(ctx, page.wikipedia, params, &r)
 	if err != nil {
 		return nil, err
 	}
 	categoriesRequest := new(CategoriesRequest)
-	categoriesRequest.cont, err = parseCont(f)
-	if err != nil {
-		return nil, err
-	}
+	categoriesRequest.cont = parseCont(&r)
 
-	if v, ok := f.(map[string]interface{}); ok {
-		if query, ok := v["query"].(map[string]interface{}); ok {
-			if pages, ok := query["pages"].(map[string]interface{}); ok {
-				for _, page := range pages {
-					if v, ok := page.(map[string]interface{}); ok {
-						if categories, ok := v["categories"].([]interface{}); ok {
-							for _, elI := range categories {
-								if el, ok := elI.(map[string]interface{}); ok {
-									if name, ok := el["title"].(string); ok {
-										categoriesRequest.categories = append(categoriesRequest.categories, Category{Name: name})
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	for _, p := range r.pages() {
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		for _, el := range p.Categories {
+			categoriesRequest.categories = append(categoriesRequest.categories, Category{Name: el.Title})
 		}
 	}
 	if len(categoriesRequest.categories) == 0 {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+		return nil, invalidResponse()
 	}
 	return categoriesRequest, nil
 
 }
 
 func (page *PageClient) Categories() <-chan CategoryRequest {
+	return page.CategoriesContext(context.Background())
+}
+
+func (page *PageClient) CategoriesContext(ctx context.Context) <-chan CategoryRequest {
 	ch := make(chan CategoryRequest)
 	go func() {
 		defer close(ch)
-		cont := make(map[string][]string)
-		for {
-			categoriesRequest, err := page.requestCategories(cont)
-			if err != nil {
-				ch <- CategoryRequest{Err: err}
+		for category, err := range page.CategoriesSeqContext(ctx) {
+			select {
+			case ch <- CategoryRequest{Category: category, Err: err}:
+			case <-ctx.Done():
 				return
 			}
-			for _, category := range categoriesRequest.categories {
-				ch <- CategoryRequest{Category: category}
-			}
-			cont = categoriesRequest.cont
-			if len(cont) == 0 {
-				break
-			}
 		}
 	}()
 	return ch
 }
 
-func (page *PageClient) Sections() ([]string, error) {
-	id, err := page.Id()
-	if err != nil {
-		return nil, err
+func (page *PageClient) CategoriesSeq() iter.Seq2[Category, error] {
+	return page.CategoriesSeqContext(context.Background())
+}
+
+func (page *PageClient) CategoriesSeqContext(ctx context.Context) iter.Seq2[Category, error] {
+	return func(yield func(Category, error) bool) {
+		if snapshot := page.cachedSnapshot(); snapshot != nil && snapshot.withCategories {
+			for _, category := range snapshot.Categories {
+				if !yield(category, nil) {
+					return
+				}
+			}
+			return
+		}
+		for category, err := range page.ResumeCategoriesContext(ctx, &Cursor{}) {
+			if !yield(category, err) {
+				return
+			}
+		}
 	}
-	var f interface{}
-	err = query(page.wikipedia, map[string][]string{
-		"prop":   {"sections"},
-		"format": {"json"},
-		"action": {"parse"},
-		"pageid": {id},
-	}, &f)
+}
+
+func (page *PageClient) ResumeCategories(cursor *Cursor) iter.Seq2[Category, error] {
+	return page.ResumeCategoriesContext(context.Background(), cursor)
+}
+
+func (page *PageClient) ResumeCategoriesContext(ctx context.Context, cursor *Cursor) iter.Seq2[Category, error] {
+	return resume(ctx, cursor, func(ctx context.Context, params map[string][]string) ([]Category, map[string][]string, error) {
+		categoriesRequest, err := page.requestCategories(ctx, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return categoriesRequest.categories, categoriesRequest.cont, nil
+	})
+}
+
+func (page *PageClient) Sections() ([]string, error) {
+	return page.SectionsContext(context.Background())
+}
+
+func (page *PageClient) SectionsContext(ctx context.Context) ([]string, error) {
+	sections, err := page.requestSections(ctx)
 	if err != nil {
 		return nil, err
 	}
-
 	titles := make([]string, 0)
-	if v, ok := f.(map[string]interface{}); ok {
-		if parse, ok := v["parse"].(map[string]interface{}); ok {
-			if sections, ok := parse["sections"].([]interface{}); ok {
-				for _, section := range sections {
-					if v, ok := section.(map[string]interface{}); ok {
-						if line, ok := v["line"].(string); ok {
-							titles = append(titles, line)
-						}
-					}
-				}
-			}
-		}
+	for _, section := range sections {
+		titles = append(titles, section.Line)
 	}
 	if len(titles) == 0 {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+		return nil, invalidResponse()
 	}
 	return titles, nil
 }
 
 func (page *PageClient) SectionContent(title string) (string, error) {
-	content, err := page.Content()
+	return page.SectionContentContext(context.Background(), title)
+}
+
+func (page *PageClient) SectionContentContext(ctx context.Context, title string) (string, error) {
+	tree, err := page.SectionTreeContext(ctx)
 	if err != nil {
 		return "", err
 	}
-	headr := fmt.Sprintf("== %s ==", title)
-	index := strings.Index(content, headr)
-	if index == -1 {
+	section := tree.ByTitle(title)
+	if section == nil {
 		return "", nil
 	}
-	index += len(headr)
-	end := strings.Index(content[index:], "==")
-	if end == -1 {
-		end = len(content)
-	} else {
-		end += index
-	}
-	return content[index:end], nil
+	return page.ReadSectionContext(ctx, section, SectionOptions{})
 }
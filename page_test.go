@@ -1,6 +1,10 @@
 package wikipedia
 
+import "context"
+import "encoding/json"
+import "errors"
 import "fmt"
+import "net/http"
 import "strings"
 import "testing"
 
@@ -308,3 +312,335 @@ func TestSectionContent(t *testing.T) {
 		return
 	}
 }
+
+func TestLinksContextCancel(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"continue":{"plcontinue":"1|0|B","continue":"||"},"query":{"pages":[{"pageid":1,"title":"A","links":[{"ns":0,"title":"B"},{"ns":0,"title":"C"}]}]}}`)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := NewPage(w, "A").LinksContext(ctx)
+	linkRequest := <-ch
+	if linkRequest.Err != nil {
+		t.Error(fmt.Sprintf("error getting page links %s", linkRequest.Err))
+		return
+	}
+	cancel()
+	for range ch {
+	}
+}
+
+func TestLinksSeqStopsFetching(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(rw, `{"continue":{"plcontinue":"1|0|B","continue":"||"},"query":{"pages":[{"pageid":1,"title":"A","links":[{"ns":0,"title":"B"},{"ns":0,"title":"C"}]}]}}`)
+	})
+	c := 0
+	for link, err := range NewPage(w, "A").LinksSeq() {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting page links %s", err))
+			return
+		}
+		if len(link.Title) == 0 {
+			t.Error("got link with no title")
+			return
+		}
+		c++
+		if c == 3 {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Error(fmt.Sprintf("expected 2 requests, got %d", requests))
+		return
+	}
+}
+
+func TestHtmlContentWarnings(t *testing.T) {
+	t.Parallel()
+	warnings := make([]Warning, 0)
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"warnings":{"main":{"warnings":"Subscribe to the mediawiki-api-announce mailing list."},"parse":{"warnings":"Unrecognized value for parameter \"prop\": foo.\nThe \"disablepp\" parameter has been deprecated."}},"parse":{"title":"A","pageid":1,"text":"<p>A</p>"}}`)
+	}, WithWarningHandler(func(warning Warning) {
+		warnings = append(warnings, warning)
+	}))
+	content, err := NewPage(w, "A").HtmlContent()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting page html content %s", err))
+		return
+	}
+	if content != "<p>A</p>" {
+		t.Error("got wrong html content")
+		return
+	}
+	if len(warnings) != 3 {
+		t.Error(fmt.Sprintf("expected 3 warnings, got %d", len(warnings)))
+		return
+	}
+	if warnings[2].Module != "parse" || strings.Contains(warnings[2].Info, "disablepp") == false {
+		t.Error("expected disablepp deprecation warning")
+		return
+	}
+}
+
+func TestDisambiguation(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("plcontinue") == "" {
+			fmt.Fprint(rw, `{"continue":{"plcontinue":"1|0|Mercury (planet)","continue":"||pageprops"},"query":{"pages":[{"pageid":1,"title":"Mercury","pageprops":{"disambiguation":""},"links":[{"ns":0,"title":"Mercury (element)"}]}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"Mercury","links":[{"ns":0,"title":"Mercury (planet)"}]}]}}`)
+	})
+	page := NewPage(w, "Mercury")
+	disambiguation, err := page.IsDisambiguation()
+	if err != nil {
+		t.Error(fmt.Sprintf("error checking disambiguation %s", err))
+		return
+	}
+	if !disambiguation {
+		t.Error("expected page to be a disambiguation page")
+		return
+	}
+	options, err := page.DisambiguationOptions()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting disambiguation options %s", err))
+		return
+	}
+	if len(options) != 2 || options[0] != "Mercury (element)" || options[1] != "Mercury (planet)" {
+		t.Error(fmt.Sprintf("got wrong disambiguation options %v", options))
+		return
+	}
+}
+
+func TestNotDisambiguation(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"Argentina","links":[{"ns":0,"title":"Buenos Aires"}]}]}}`)
+	})
+	page := NewPage(w, "Argentina")
+	disambiguation, err := page.IsDisambiguation()
+	if err != nil || disambiguation {
+		t.Error("expected page not to be a disambiguation page")
+		return
+	}
+	options, err := page.DisambiguationOptions()
+	if err != nil || len(options) != 0 {
+		t.Error("expected no disambiguation options")
+		return
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"normalized":[{"from":"law_of_triviality","to":"Law of triviality"}],"redirects":[{"from":"Bikeshed","to":"Law of triviality"},{"from":"Law of triviality","to":"Bikeshed"}],"pages":[{"pageid":4138548,"title":"Law of triviality"}]}}`)
+	})
+	_, err := NewPage(w, "law_of_triviality").Redirects()
+	if errors.Is(err, ErrRedirectLoop) == false {
+		t.Error(fmt.Sprintf("expected redirect loop error, got %v", err))
+		return
+	}
+
+	w = newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"normalized":[{"from":"bikeshedding","to":"Bikeshedding"}],"redirects":[{"from":"Bikeshed","to":"Law of triviality","tofragment":"Examples"},{"from":"Bikeshedding","to":"Bikeshed"}],"pages":[{"pageid":4138548,"title":"Law of triviality"}]}}`)
+	})
+	redirects, err := NewPage(w, "bikeshedding").Redirects()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting redirects %s", err))
+		return
+	}
+	expected := []Redirect{
+		{From: "bikeshedding", To: "Bikeshedding", Normalized: true},
+		{From: "Bikeshedding", To: "Bikeshed"},
+		{From: "Bikeshed", To: "Law of triviality", Fragment: "Examples"},
+	}
+	if len(redirects) != len(expected) {
+		t.Error(fmt.Sprintf("got wrong redirect chain %v", redirects))
+		return
+	}
+	for i := range expected {
+		if redirects[i] != expected[i] {
+			t.Error(fmt.Sprintf("got wrong redirect %d: %v", i, redirects[i]))
+			return
+		}
+	}
+}
+
+func TestNoFollowRedirects(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["redirects"]; ok {
+			t.Error("did not expect redirects parameter")
+		}
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"Bikeshed","redirect":true}]}}`)
+	})
+	w.SetFollowRedirects(false)
+	testPageId(t, NewPage(w, "Bikeshed"), "1")
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("prop") != "info|pageprops|extracts|revisions|categories" {
+			t.Error(fmt.Sprintf("got wrong prop %s", r.URL.Query().Get("prop")))
+		}
+		if r.URL.Query().Get("clcontinue") == "" {
+			fmt.Fprint(rw, `{"continue":{"clcontinue":"4138548|B","continue":"||"},"query":{"pages":[{"pageid":4138548,"title":"Law of triviality","fullurl":"https://en.wikipedia.org/wiki/Law_of_triviality","extract":"Intro text.\n\n\n== Examples ==\nExample text.","pageprops":{"wikibase_item":"Q1"},"revisions":[{"revid":10,"parentid":9,"user":"U","timestamp":"2020-01-02T03:04:05Z","comment":"c"}],"categories":[{"ns":14,"title":"Category:A"}]}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":4138548,"title":"Law of triviality","categories":[{"ns":14,"title":"Category:B"}]}]}}`)
+	})
+	page := NewPage(w, "Law of triviality")
+	snapshot, err := page.Load(LoadOptions{Categories: true})
+	if err != nil {
+		t.Error(fmt.Sprintf("error loading page %s", err))
+		return
+	}
+	if snapshot.Url != "https://en.wikipedia.org/wiki/Law_of_triviality" || snapshot.PageProps["wikibase_item"] != "Q1" {
+		t.Error("got wrong snapshot")
+		return
+	}
+	if snapshot.LastRevision.Id != 10 || snapshot.LastRevision.ParentId != 9 || snapshot.LastRevision.Timestamp.Year() != 2020 {
+		t.Error("got wrong last revision")
+		return
+	}
+	testPageId(t, page, "4138548")
+	testPageTitle(t, page, "Law of triviality")
+	summary, err := page.Summary()
+	if err != nil || summary != "Intro text." {
+		t.Error(fmt.Sprintf("got wrong summary %q", summary))
+		return
+	}
+	c := 0
+	for category, err := range page.CategoriesSeq() {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting page categories %s", err))
+			return
+		}
+		if len(category.Name) == 0 {
+			t.Error("got category with no name")
+			return
+		}
+		c++
+	}
+	if c != 2 {
+		t.Error("expected 2 categories")
+		return
+	}
+	if requests != 2 {
+		t.Error(fmt.Sprintf("expected 2 requests, got %d", requests))
+		return
+	}
+}
+
+func TestLoadPinnedRevision(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		t.Error("did not expect a request")
+		fmt.Fprint(rw, `{}`)
+	})
+	_, err := NewPageAtRevision(w, 10).Load(LoadOptions{})
+	if errors.Is(err, ErrParameter) == false {
+		t.Error(fmt.Sprintf("expected parameter error, got %v", err))
+		return
+	}
+}
+
+func TestLoadImages(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("prop") == "imageinfo" {
+			if r.URL.Query().Get("titles") != "File:A.jpg|File:B.png" {
+				t.Error(fmt.Sprintf("got wrong titles %s", r.URL.Query().Get("titles")))
+			}
+			fmt.Fprint(rw, `{"query":{"pages":[{"ns":6,"title":"File:A.jpg","imageinfo":[{"url":"https://upload.wikimedia.org/A.jpg","descriptionurl":"https://en.wikipedia.org/wiki/File:A.jpg"}]},{"ns":6,"title":"File:B.png","imageinfo":[{"url":"https://upload.wikimedia.org/B.png","descriptionurl":"https://en.wikipedia.org/wiki/File:B.png"}]}]}}`)
+			return
+		}
+		if r.URL.Query().Get("prop") != "info|pageprops|extracts|revisions|images" {
+			t.Error(fmt.Sprintf("got wrong prop %s", r.URL.Query().Get("prop")))
+		}
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","extract":"Text.","images":[{"ns":6,"title":"File:A.jpg"},{"ns":6,"title":"File:B.png"}]}]}}`)
+	})
+	page := NewPage(w, "A")
+	if _, err := page.Load(LoadOptions{Images: true}); err != nil {
+		t.Error(fmt.Sprintf("error loading page %s", err))
+		return
+	}
+	images := make([]Image, 0)
+	for image, err := range page.ImagesSeq() {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting page images %s", err))
+			return
+		}
+		images = append(images, image)
+	}
+	if len(images) != 2 || images[0].Url != "https://upload.wikimedia.org/A.jpg" || images[1].DescriptionUrl != "https://en.wikipedia.org/wiki/File:B.png" {
+		t.Error(fmt.Sprintf("got wrong images %v", images))
+		return
+	}
+	if requests != 2 {
+		t.Error(fmt.Sprintf("expected 2 requests, got %d", requests))
+		return
+	}
+}
+
+func TestResumeLinks(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("plcontinue") == "1|0|D" {
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","links":[{"ns":0,"title":"D"},{"ns":0,"title":"E"}]}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"continue":{"plcontinue":"1|0|D","continue":"||"},"query":{"pages":[{"pageid":1,"title":"A","links":[{"ns":0,"title":"B"},{"ns":0,"title":"C"}]}]}}`)
+	})
+	page := NewPage(w, "A")
+	cursor := &Cursor{}
+	titles := make([]string, 0)
+	for link, err := range page.ResumeLinks(cursor) {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting page links %s", err))
+			return
+		}
+		titles = append(titles, link.Title)
+		if len(titles) == 1 {
+			break
+		}
+	}
+	saved, err := json.Marshal(cursor)
+	if err != nil {
+		t.Error(fmt.Sprintf("error serializing cursor %s", err))
+		return
+	}
+	cursor = &Cursor{}
+	if err := json.Unmarshal(saved, cursor); err != nil {
+		t.Error(fmt.Sprintf("error deserializing cursor %s", err))
+		return
+	}
+	for link, err := range page.ResumeLinks(cursor) {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting page links %s", err))
+			return
+		}
+		titles = append(titles, link.Title)
+	}
+	if strings.Join(titles, ",") != "B,C,D,E" {
+		t.Error(fmt.Sprintf("got wrong links %v", titles))
+		return
+	}
+	if !cursor.Done {
+		t.Error("expected cursor to be done")
+		return
+	}
+	for range page.ResumeLinks(cursor) {
+		t.Error("expected no links from a finished cursor")
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package wikipedia
+
+import "context"
+import "encoding/json"
+import "iter"
+
+type QueryResult struct {
+	Raw      json.RawMessage
+	Continue map[string]string
+}
+
+func (r *QueryResult) Decode(v interface{}) error {
+	err := json.Unmarshal(r.Raw, v)
+	if err != nil {
+		return newError(DecodeError, err)
+	}
+	return nil
+}
+
+func (w *WikipediaClient) Query(params map[string][]string) (*QueryResult, error) {
+	return w.QueryContext(context.Background(), params)
+}
+
+func (w *WikipediaClient) QueryContext(ctx context.Context, params map[string][]string) (*QueryResult, error) {
+	q := make(map[string][]string, len(params)+1)
+	for k, v := range params {
+		q[k] = v
+	}
+	if _, ok := q["format"]; !ok {
+		q["format"] = []string{"json"}
+	}
+	var cache Cache
+	if w.cacheQueries {
+		cache = w.Cache()
+	}
+	var raw json.RawMessage
+	err := queryWith(ctx, w, cache, q, &raw)
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Continue map[string]continueValue `json:"continue"`
+	}
+	err = json.Unmarshal(raw, &r)
+	if err != nil {
+		return nil, newError(DecodeError, err)
+	}
+	result := &QueryResult{Raw: raw, Continue: make(map[string]string, len(r.Continue))}
+	for k, v := range r.Continue {
+		result.Continue[k] = string(v)
+	}
+	return result, nil
+}
+
+func (w *WikipediaClient) QueryAll(params map[string][]string) iter.Seq2[*QueryResult, error] {
+	return w.QueryAllContext(context.Background(), params)
+}
+
+func (w *WikipediaClient) QueryAllContext(ctx context.Context, params map[string][]string) iter.Seq2[*QueryResult, error] {
+	return func(yield func(*QueryResult, error) bool) {
+		cont := map[string]string{"continue": ""}
+		for {
+			q := make(map[string][]string, len(params)+len(cont))
+			for k, v := range params {
+				q[k] = v
+			}
+			for k, v := range cont {
+				q[k] = []string{v}
+			}
+			result, err := w.QueryContext(ctx, q)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(result, nil) {
+				return
+			}
+			cont = result.Continue
+			if len(cont) == 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package wikipedia
+
+import "fmt"
+import "net/http"
+import "testing"
+import "time"
+
+func TestQueryAll(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list") != "search" || r.URL.Query().Get("srsearch") != "hello" {
+			t.Error("Expected caller parameters to be kept")
+		}
+		switch r.URL.Query().Get("sroffset") {
+		case "":
+			fmt.Fprint(rw, `{"continue":{"sroffset":10,"continue":"-||"},"query":{"search":[{"title":"A"}]}}`)
+		case "10":
+			fmt.Fprint(rw, `{"continue":{"sroffset":20,"continue":"-||"},"query":{"search":[{"title":"B"}]}}`)
+		case "20":
+			fmt.Fprint(rw, `{"query":{"search":[{"title":"C"}]}}`)
+		default:
+			t.Error(fmt.Sprintf("Got wrong sroffset %s", r.URL.Query().Get("sroffset")))
+			fmt.Fprint(rw, `{}`)
+		}
+	})
+	titles := make([]string, 0)
+	for result, err := range w.QueryAll(map[string][]string{
+		"action":   {"query"},
+		"list":     {"search"},
+		"srsearch": {"hello"},
+	}) {
+		if err != nil {
+			t.Error(fmt.Sprintf("Got error %s", err))
+			return
+		}
+		var response struct {
+			Query struct {
+				Search []struct {
+					Title string `json:"title"`
+				} `json:"search"`
+			} `json:"query"`
+		}
+		err = result.Decode(&response)
+		if err != nil {
+			t.Error(fmt.Sprintf("Got error %s", err))
+			return
+		}
+		for _, search := range response.Query.Search {
+			titles = append(titles, search.Title)
+		}
+	}
+	if len(titles) != 3 || titles[0] != "A" || titles[2] != "C" {
+		t.Error(fmt.Sprintf("Got wrong titles %v", titles))
+		return
+	}
+}
+
+func TestQueryRaw(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"batchcomplete":true,"query":{"allpages":[{"title":"A"}]}}`)
+	})
+	result, err := w.Query(map[string][]string{"action": {"query"}, "list": {"allpages"}})
+	if err != nil {
+		t.Error(fmt.Sprintf("Got error %s", err))
+		return
+	}
+	if string(result.Raw) != `{"batchcomplete":true,"query":{"allpages":[{"title":"A"}]}}` || len(result.Continue) != 0 {
+		t.Error("Got wrong raw result")
+		return
+	}
+}
+
+func TestQueryFormatVersion(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("formatversion") != "1" {
+			t.Error(fmt.Sprintf("Got wrong formatversion %s", r.URL.Query().Get("formatversion")))
+		}
+		fmt.Fprint(rw, `{"continue":{"plcontinue":"1|0|B","continue":"||"},"query":{"pages":{"1":{"pageid":1,"title":"A","links":[{"ns":0,"title":"A"}]}}}}`)
+	})
+	result, err := w.Query(map[string][]string{"action": {"query"}, "prop": {"links"}, "titles": {"A"}, "formatversion": {"1"}})
+	if err != nil {
+		t.Error(fmt.Sprintf("Got error %s", err))
+		return
+	}
+	if result.Continue["plcontinue"] != "1|0|B" {
+		t.Error(fmt.Sprintf("Got wrong continue %v", result.Continue))
+		return
+	}
+}
+
+func TestQueryCacheOptIn(t *testing.T) {
+	t.Parallel()
+	for _, cacheQueries := range []bool{false, true} {
+		requests := 0
+		w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(rw, `{"query":{"allpages":[{"title":"A"}]}}`)
+		}, WithCache(NewMemoryCache(10, time.Hour)), WithQueryCache(cacheQueries))
+		for i := 0; i < 2; i++ {
+			_, err := w.Query(map[string][]string{"action": {"query"}, "list": {"allpages"}})
+			if err != nil {
+				t.Error(fmt.Sprintf("Got error %s", err))
+				return
+			}
+		}
+		expected := 2
+		if cacheQueries {
+			expected = 1
+		}
+		if requests != expected {
+			t.Error(fmt.Sprintf("expected %d requests with query cache %v, got %d", expected, cacheQueries, requests))
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package wikipedia
+
+import "context"
+import "math/rand/v2"
+import "sync"
+import "time"
+
+type RetryPolicy struct {
+	MaxRetries          int
+	BaseDelay, MaxDelay time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter >= 0 {
+		return retryAfter, p.MaxDelay <= 0 || retryAfter <= p.MaxDelay
+	}
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package wikipedia
+
+import "errors"
+import "fmt"
+import "net/http"
+import "testing"
+import "time"
+
+func TestRetryServerError(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(rw, `{"query":{"search":[{"title":"Hello"}]}}`)
+	}, WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}))
+	results, err := w.Search("hello")
+	if err != nil {
+		t.Error(fmt.Sprintf("Got error %s", err))
+		return
+	}
+	if contains(results, "Hello") == false || requests != 3 {
+		t.Error("Expected results after retrying")
+		return
+	}
+}
+
+func TestRetryMaxlag(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("maxlag") != "5" {
+			t.Error("Expected maxlag parameter")
+		}
+		if requests == 1 {
+			rw.Header().Set("Retry-After", "0")
+			fmt.Fprint(rw, `{"error":{"code":"maxlag","info":"Waiting for a database server: 6 seconds lagged."}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"query":{"search":[{"title":"Hello"}]}}`)
+	}, WithRetryPolicy(RetryPolicy{MaxRetries: 1, BaseDelay: time.Hour}))
+	results, err := w.Search("hello")
+	if err != nil {
+		t.Error(fmt.Sprintf("Got error %s", err))
+		return
+	}
+	if contains(results, "Hello") == false || requests != 2 {
+		t.Error("Expected results after retrying")
+		return
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}, WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrRateLimited) == false || requests != 3 {
+		t.Error(fmt.Sprintf("Expected rate limit error after 3 requests, got %v after %d", err, requests))
+		return
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			rw.Header().Set("Retry-After", "86400")
+			fmt.Fprint(rw, `{"error":{"code":"maxlag","info":"Waiting for a database server: 6 seconds lagged."}}`)
+			return
+		}
+		rw.Header().Set("Retry-After", "86400")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}, WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}))
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrRateLimited) == false || errors.Is(err, ErrApi) == false || requests != 1 {
+		t.Error(fmt.Sprintf("Expected rate limit error after 1 request, got %v after %d", err, requests))
+		return
+	}
+	_, err = w.Search("world")
+	if errors.Is(err, ErrRateLimited) == false || requests != 2 {
+		t.Error(fmt.Sprintf("Expected rate limit error after 2 requests, got %v after %d", err, requests))
+		return
+	}
+}
+
+func TestNoRetryClientError(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusBadRequest)
+	}, WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrResponse) == false || requests != 1 {
+		t.Error("Expected a single failed request")
+		return
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"search":[]}}`)
+	}, WithRateLimit(20*time.Millisecond))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := w.Search("hello")
+		if err != nil {
+			t.Error(fmt.Sprintf("Got error %s", err))
+			return
+		}
+	}
+	if time.Since(start) < 40*time.Millisecond {
+		t.Error("Expected requests to be spaced out")
+		return
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	policy := RetryPolicy{MaxRetries: 10, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	if delay, ok := policy.delay(0, 3*time.Second); delay != 3*time.Second || ok == false {
+		t.Error("Expected Retry-After to be honored")
+		return
+	}
+	if _, ok := policy.delay(0, 7*time.Second); ok {
+		t.Error("Expected Retry-After above MaxDelay to be refused")
+		return
+	}
+	for attempt := 0; attempt < 10; attempt++ {
+		delay, ok := policy.delay(attempt, -1)
+		if ok == false || delay > 4*time.Second || delay < (time.Second<<min(attempt, 2))/2 {
+			t.Error(fmt.Sprintf("Got wrong delay %s for attempt %d", delay, attempt))
+			return
+		}
+	}
+	if parseRetryAfter("3") != 3*time.Second || parseRetryAfter("") != -1 {
+		t.Error("Expected Retry-After seconds to be parsed")
+		return
+	}
+}
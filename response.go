@@ -0,0 +1,194 @@
+package wikipedia
+
+import "bytes"
+import "encoding/json"
+import "errors"
+import "fmt"
+import "strconv"
+import "time"
+
+type apiResponse struct {
+	Continue map[string]continueValue `json:"continue"`
+	Query    *apiQuery                `json:"query"`
+	Parse    *apiParse                `json:"parse"`
+}
+
+type continueValue string
+
+func (v *continueValue) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case json.Number:
+		*v = continueValue(value.String())
+	case string:
+		*v = continueValue(value)
+	case bool:
+		if value {
+			*v = "1"
+		} else {
+			*v = "0"
+		}
+	case nil:
+		*v = ""
+	default:
+		return errors.New("invalid continue parameter")
+	}
+	return nil
+}
+
+type apiQuery struct {
+	Normalized []apiRedirect `json:"normalized"`
+	Redirects  []apiRedirect `json:"redirects"`
+	Pages      []apiPage     `json:"pages"`
+	Search     []apiTitle    `json:"search"`
+	Geosearch  []apiTitle    `json:"geosearch"`
+	Random     []apiTitle    `json:"random"`
+	Languages  []apiLanguage `json:"languages"`
+}
+
+type apiRedirect struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ToFragment string `json:"tofragment"`
+}
+
+type apiTitle struct {
+	Title string `json:"title"`
+}
+
+type apiLanguage struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type apiPage struct {
+	PageId        int64             `json:"pageid"`
+	Title         string            `json:"title"`
+	Missing       bool              `json:"missing"`
+	Invalid       bool              `json:"invalid"`
+	InvalidReason string            `json:"invalidreason"`
+	FullUrl       string            `json:"fullurl"`
+	LastRevId     int64             `json:"lastrevid"`
+	Extract       string            `json:"extract"`
+	PageProps     map[string]string `json:"pageprops"`
+	Revisions     []apiRevision     `json:"revisions"`
+	Categories    []apiTitle        `json:"categories"`
+	Links         []apiTitle        `json:"links"`
+	Images        []apiTitle        `json:"images"`
+	ExtLinks      []apiExtLink      `json:"extlinks"`
+	ImageInfo     []apiImageInfo    `json:"imageinfo"`
+}
+
+type apiRevision struct {
+	RevId     int64              `json:"revid"`
+	ParentId  int64              `json:"parentid"`
+	Timestamp string             `json:"timestamp"`
+	User      string             `json:"user"`
+	Comment   string             `json:"comment"`
+	Content   string             `json:"content"`
+	Size      int                `json:"size"`
+	Sha1      string             `json:"sha1"`
+	Tags      []string           `json:"tags"`
+	Slots     map[string]apiSlot `json:"slots"`
+}
+
+type apiSlot struct {
+	ContentModel string `json:"contentmodel"`
+	Content      string `json:"content"`
+}
+
+type apiExtLink struct {
+	Url string `json:"url"`
+}
+
+type apiImageInfo struct {
+	Url            string `json:"url"`
+	DescriptionUrl string `json:"descriptionurl"`
+}
+
+type apiParse struct {
+	Title         string         `json:"title"`
+	PageId        int64          `json:"pageid"`
+	RevId         int64          `json:"revid"`
+	Text          string         `json:"text"`
+	Wikitext      string         `json:"wikitext"`
+	Sections      []apiSection   `json:"sections"`
+	Links         []apiParseLink `json:"links"`
+	ExternalLinks []string       `json:"externallinks"`
+}
+
+type apiParseLink struct {
+	Ns    int    `json:"ns"`
+	Title string `json:"title"`
+}
+
+type apiSection struct {
+	TocLevel   int    `json:"toclevel"`
+	Level      string `json:"level"`
+	Line       string `json:"line"`
+	Number     string `json:"number"`
+	Index      string `json:"index"`
+	FromTitle  string `json:"fromtitle"`
+	ByteOffset int    `json:"byteoffset"`
+	Anchor     string `json:"anchor"`
+}
+
+func invalidResponse() error {
+	return newError(ResponseError, errors.New("invalid json response"))
+}
+
+func (r *apiResponse) pages() []apiPage {
+	if r.Query == nil {
+		return nil
+	}
+	return r.Query.Pages
+}
+
+func (r *apiResponse) firstPage() (*apiPage, error) {
+	pages := r.pages()
+	if len(pages) == 0 {
+		return nil, invalidResponse()
+	}
+	if err := pages[0].err(); err != nil {
+		return nil, err
+	}
+	return &pages[0], nil
+}
+
+func (page *apiPage) err() error {
+	if page.Missing {
+		return newError(MissingPageError, fmt.Errorf("page %q does not exist", page.Title))
+	}
+	if page.Invalid {
+		return newError(InvalidTitleError, fmt.Errorf("%q: %s", page.Title, page.InvalidReason))
+	}
+	return nil
+}
+
+func (page *apiPage) id() string {
+	return strconv.FormatInt(page.PageId, 10)
+}
+
+func (page *apiPage) isDisambiguation() bool {
+	_, ok := page.PageProps["disambiguation"]
+	return ok
+}
+
+func (revision apiRevision) revision() Revision {
+	timestamp, _ := time.Parse(time.RFC3339, revision.Timestamp)
+	return Revision{
+		Id:        revision.RevId,
+		ParentId:  revision.ParentId,
+		Timestamp: timestamp,
+		User:      revision.User,
+		Comment:   revision.Comment,
+		Size:      revision.Size,
+		Sha1:      revision.Sha1,
+		Tags:      revision.Tags,
+	}
+}
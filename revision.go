@@ -0,0 +1,149 @@
+package wikipedia
+
+import "context"
+import "fmt"
+import "iter"
+import "time"
+
+type Revision struct {
+	Id, ParentId  int64
+	Timestamp     time.Time
+	User, Comment string
+	Size          int
+	Sha1          string
+	Tags          []string
+}
+
+type RevisionDirection int
+
+const (
+	Older RevisionDirection = iota
+	Newer
+)
+
+type RevisionOptions struct {
+	Since, Until time.Time
+	User         string
+	ExcludeUser  string
+	Direction    RevisionDirection
+}
+
+func (opts RevisionOptions) params() map[string][]string {
+	params := map[string][]string{
+		"prop":    {"revisions"},
+		"rvprop":  {"ids|timestamp|user|comment|size|sha1|tags"},
+		"rvlimit": {"max"},
+		"rvdir":   {"older"},
+	}
+	start, end := opts.Until, opts.Since
+	if opts.Direction == Newer {
+		params["rvdir"] = []string{"newer"}
+		start, end = opts.Since, opts.Until
+	}
+	if !start.IsZero() {
+		params["rvstart"] = []string{start.UTC().Format(time.RFC3339)}
+	}
+	if !end.IsZero() {
+		params["rvend"] = []string{end.UTC().Format(time.RFC3339)}
+	}
+	if opts.User != "" {
+		params["rvuser"] = []string{opts.User}
+	}
+	if opts.ExcludeUser != "" {
+		params["rvexcludeuser"] = []string{opts.ExcludeUser}
+	}
+	return params
+}
+
+func (page *PageClient) historyParam(ctx context.Context) (string, string, error) {
+	if page.revision == 0 {
+		k, v := page.queryParam()
+		return k, v, nil
+	}
+	id, err := page.IdContext(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return "pageids", id, nil
+}
+
+func (page *PageClient) requestRevisions(ctx context.Context, opts RevisionOptions, params map[string][]string) ([]Revision, map[string][]string, error) {
+	k, v, err := page.historyParam(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var r apiResponse
+	if len(params) == 0 {
+		params["continue"] = []string{""}
+	}
+	for k, v := range opts.params() {
+		params[k] = v
+	}
+	params["format"] = []string{"json"}
+	params["action"] = []string{"query"}
+	params[k] = []string{v}
+	err = query(ctx, page.wikipedia, params, &r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := r.firstPage()
+	if err != nil {
+		return nil, nil, err
+	}
+	revisions := make([]Revision, 0, len(p.Revisions))
+	for _, revision := range p.Revisions {
+		revisions = append(revisions, revision.revision())
+	}
+	return revisions, parseCont(&r), nil
+}
+
+func (page *PageClient) Revisions(opts RevisionOptions) iter.Seq2[Revision, error] {
+	return page.RevisionsContext(context.Background(), opts)
+}
+
+func (page *PageClient) RevisionsContext(ctx context.Context, opts RevisionOptions) iter.Seq2[Revision, error] {
+	return resume(ctx, &Cursor{}, func(ctx context.Context, params map[string][]string) ([]Revision, map[string][]string, error) {
+		return page.requestRevisions(ctx, opts, params)
+	})
+}
+
+func (page *PageClient) AsOf(t time.Time) (Page, error) {
+	return page.AsOfContext(context.Background(), t)
+}
+
+func (page *PageClient) AsOfContext(ctx context.Context, t time.Time) (Page, error) {
+	k, v, err := page.historyParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var r apiResponse
+	err = query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":    {"revisions"},
+		"rvprop":  {"ids|timestamp"},
+		"rvlimit": {"1"},
+		"rvdir":   {"older"},
+		"rvstart": {t.UTC().Format(time.RFC3339)},
+		"format":  {"json"},
+		"action":  {"query"},
+		k:         {v},
+	}), &r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return nil, err
+	}
+	p, err := r.firstPage()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Revisions) == 0 {
+		return nil, newError(MissingPageError, fmt.Errorf("page %q has no revision before %s", p.Title, t.UTC().Format(time.RFC3339)))
+	}
+	return &PageClient{
+		wikipedia: page.wikipedia,
+		title:     p.Title,
+		id:        p.id(),
+		revision:  p.Revisions[0].RevId,
+	}, nil
+}
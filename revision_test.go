@@ -0,0 +1,178 @@
+package wikipedia
+
+import "errors"
+import "fmt"
+import "net/http"
+import "testing"
+import "time"
+
+func TestRevisions(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("rvdir") != "newer" || q.Get("rvstart") != "2020-01-01T00:00:00Z" || q.Get("rvend") != "2021-01-01T00:00:00Z" || q.Get("rvuser") != "Bob" {
+			t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+		}
+		if q.Get("rvcontinue") == "20200601000000|12" {
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":12,"parentid":11,"user":"Bob","timestamp":"2020-06-01T00:00:00Z","size":120,"sha1":"b","comment":"more","tags":[]}]}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"continue":{"rvcontinue":"20200601000000|12","continue":"||"},"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":10,"parentid":0,"user":"Bob","timestamp":"2020-02-01T00:00:00Z","size":100,"sha1":"a","comment":"create","tags":["mobile edit"]},{"revid":11,"parentid":10,"user":"Bob","timestamp":"2020-03-01T00:00:00Z","size":110,"sha1":"c","comment":"fix","tags":[]}]}]}}`)
+	})
+	revisions := make([]Revision, 0)
+	for revision, err := range NewPage(w, "A").Revisions(RevisionOptions{
+		Since:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		User:      "Bob",
+		Direction: Newer,
+	}) {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting revisions %s", err))
+			return
+		}
+		revisions = append(revisions, revision)
+	}
+	if len(revisions) != 3 {
+		t.Error(fmt.Sprintf("expected 3 revisions, got %d", len(revisions)))
+		return
+	}
+	first := revisions[0]
+	if first.Id != 10 || first.Size != 100 || first.Sha1 != "a" || len(first.Tags) != 1 || first.Comment != "create" {
+		t.Error(fmt.Sprintf("got wrong revision %+v", first))
+		return
+	}
+	if !revisions[2].Timestamp.Equal(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)) || revisions[2].ParentId != 11 {
+		t.Error(fmt.Sprintf("got wrong revision %+v", revisions[2]))
+		return
+	}
+}
+
+func TestRevisionsOlder(t *testing.T) {
+	t.Parallel()
+	params := RevisionOptions{
+		Since: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}.params()
+	if params["rvdir"][0] != "older" || params["rvstart"][0] != "2021-01-01T00:00:00Z" || params["rvend"][0] != "2020-01-01T00:00:00Z" {
+		t.Error(fmt.Sprintf("got wrong parameters %v", params))
+		return
+	}
+}
+
+func TestPageAtRevision(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("action") != "parse" || q.Get("oldid") != "42" || q.Get("pageid") != "" {
+			t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+			return
+		}
+		switch q.Get("prop") {
+		case "text":
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":42,"text":"<div class=\"mw-parser-output\"><p>Old <b>text</b>.</p></div>"}}`)
+		case "links":
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":42,"links":[{"ns":0,"title":"B","exists":true},{"ns":4,"title":"Wikipedia:C","exists":true}]}}`)
+		case "sections":
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":42,"sections":[{"toclevel":1,"level":"2","line":"Old","number":"1","index":"1","byteoffset":5,"anchor":"Old"}]}}`)
+		}
+	})
+	page := w.PageAtRevision(42)
+	content, err := page.Content()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting content %s", err))
+		return
+	}
+	if content != "Old text." {
+		t.Error(fmt.Sprintf("got wrong content %q", content))
+		return
+	}
+	links := make([]string, 0)
+	for link, err := range page.LinksSeq() {
+		if err != nil {
+			t.Error(fmt.Sprintf("error getting links %s", err))
+			return
+		}
+		links = append(links, link.Title)
+	}
+	if len(links) != 1 || links[0] != "B" {
+		t.Error(fmt.Sprintf("got wrong links %v", links))
+		return
+	}
+	sections, err := page.Sections()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting sections %s", err))
+		return
+	}
+	if len(sections) != 1 || sections[0] != "Old" {
+		t.Error(fmt.Sprintf("got wrong sections %v", sections))
+		return
+	}
+}
+
+func TestPageAtRevisionSummary(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":42,"text":"<div class=\"mw-content-ltr mw-parser-output\" lang=\"en\" dir=\"ltr\">`+
+			`<div role=\"note\" class=\"hatnote navigation-not-searchable\">For other uses, see <a href=\"/wiki/A_(disambiguation)\">A (disambiguation)</a>.</div>\n`+
+			`<style data-mw-deduplicate=\"TemplateStyles:r1\">.mw-parser-output .infobox{float:right}</style>`+
+			`<table class=\"infobox biography vcard\"><tbody><tr><th scope=\"row\" class=\"infobox-label\">Born</th><td class=\"infobox-data\">1815</td></tr></tbody></table>\n`+
+			`<table class=\"box-Refimprove plainlinks metadata ambox\" role=\"presentation\"><tbody><tr><td class=\"mbox-text\">This article needs additional citations.</td></tr></tbody></table>\n`+
+			`<p><b>A</b> is a thing.<sup id=\"cite_ref-1\" class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup>\n</p>`+
+			`<div role=\"navigation\" class=\"navbox\"><table class=\"nowraplinks\"><tbody><tr><th>Things</th><td>A</td></tr></tbody></table></div>`+
+			`<table class=\"wikitable\"><tbody><tr><td>1</td><td>2</td></tr></tbody></table>`+
+			`</div>"}}`)
+	})
+	summary, err := w.PageAtRevision(42).Summary()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting summary %s", err))
+		return
+	}
+	if summary != "A is a thing." {
+		t.Error(fmt.Sprintf("got wrong summary %q", summary))
+		return
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("action") == "query" {
+			if q.Get("rvstart") != "2020-01-01T00:00:00Z" || q.Get("rvdir") != "older" || q.Get("titles") != "A" {
+				t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+			}
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":42,"parentid":41,"timestamp":"2019-12-30T00:00:00Z"}]}]}}`)
+			return
+		}
+		if q.Get("oldid") != "42" || q.Get("section") != "0" {
+			t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+		}
+		fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"revid":42,"text":"<p>Lead.</p>"}}`)
+	})
+	page, err := NewPage(w, "A").AsOf(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Error(fmt.Sprintf("error pinning page %s", err))
+		return
+	}
+	summary, err := page.Summary()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting summary %s", err))
+		return
+	}
+	if summary != "Lead." {
+		t.Error(fmt.Sprintf("got wrong summary %q", summary))
+		return
+	}
+}
+
+func TestAsOfBeforeCreation(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A"}]}}`)
+	})
+	_, err := NewPage(w, "A").AsOf(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, ErrMissingPage) {
+		t.Error(fmt.Sprintf("expected missing page error, got %v", err))
+		return
+	}
+}
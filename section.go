@@ -0,0 +1,232 @@
+package wikipedia
+
+import "context"
+import "fmt"
+import "strconv"
+import "strings"
+
+type Section struct {
+	Level, TocLevel int
+	Number          string
+	Anchor          string
+	Line            string
+	Index           int
+	Transcluded     bool
+	FromTitle       string
+	ByteOffset      int
+	Parent          *Section
+	Children        []*Section
+}
+
+type SectionTree struct {
+	Roots    []*Section
+	sections []*Section
+}
+
+func newSectionTree(apiSections []apiSection) *SectionTree {
+	tree := &SectionTree{
+		Roots:    make([]*Section, 0),
+		sections: make([]*Section, 0, len(apiSections)),
+	}
+	stack := make([]*Section, 0)
+	for _, s := range apiSections {
+		level, _ := strconv.Atoi(s.Level)
+		rawIndex, transcluded := strings.CutPrefix(s.Index, "T-")
+		index, err := strconv.Atoi(rawIndex)
+		if err != nil {
+			index = -1
+		}
+		section := &Section{
+			Level:       level,
+			TocLevel:    s.TocLevel,
+			Number:      s.Number,
+			Anchor:      s.Anchor,
+			Line:        s.Line,
+			Index:       index,
+			Transcluded: transcluded,
+			FromTitle:   s.FromTitle,
+			ByteOffset:  s.ByteOffset,
+			Children:    make([]*Section, 0),
+		}
+		for len(stack) > 0 && stack[len(stack)-1].TocLevel >= section.TocLevel {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			tree.Roots = append(tree.Roots, section)
+		} else {
+			section.Parent = stack[len(stack)-1]
+			section.Parent.Children = append(section.Parent.Children, section)
+		}
+		stack = append(stack, section)
+		tree.sections = append(tree.sections, section)
+	}
+	return tree
+}
+
+func (tree *SectionTree) All() []*Section {
+	return tree.sections
+}
+
+func (tree *SectionTree) ByAnchor(anchor string) *Section {
+	for _, section := range tree.sections {
+		if section.Anchor == anchor {
+			return section
+		}
+	}
+	return nil
+}
+
+func (tree *SectionTree) ByNumber(number string) *Section {
+	for _, section := range tree.sections {
+		if section.Number == number {
+			return section
+		}
+	}
+	return nil
+}
+
+func (page *PageClient) requestSections(ctx context.Context) ([]apiSection, error) {
+	k, v := page.parseParam()
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(map[string][]string{
+		"prop":   {"sections"},
+		"format": {"json"},
+		"action": {"parse"},
+		k:        {v},
+	}), &r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Parse == nil {
+		return nil, invalidResponse()
+	}
+	return r.Parse.Sections, nil
+}
+
+func (page *PageClient) SectionTree() (*SectionTree, error) {
+	return page.SectionTreeContext(context.Background())
+}
+
+func (page *PageClient) SectionTreeContext(ctx context.Context) (*SectionTree, error) {
+	sections, err := page.requestSections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSectionTree(sections), nil
+}
+
+type ContentFormat int
+
+const (
+	PlainTextFormat ContentFormat = iota
+	HtmlFormat
+	WikitextFormat
+)
+
+type SectionOptions struct {
+	Format      ContentFormat
+	Subsections bool
+}
+
+func (page *PageClient) ReadSection(section *Section, opts SectionOptions) (string, error) {
+	return page.ReadSectionContext(context.Background(), section, opts)
+}
+
+func (page *PageClient) ReadSectionContext(ctx context.Context, section *Section, opts SectionOptions) (string, error) {
+	if section != nil && (section.Transcluded || section.Index < 0) {
+		return "", newError(ParameterError, fmt.Errorf("section %q is transcluded from %s and cannot be read by index", section.Line, section.FromTitle))
+	}
+	k, v := page.parseParam()
+	params := map[string][]string{
+		"format":             {"json"},
+		"action":             {"parse"},
+		k:                    {v},
+		"section":            {"0"},
+		"disableeditsection": {"1"},
+		"prop":               {"text"},
+	}
+	if section != nil {
+		params["section"] = []string{strconv.Itoa(section.Index)}
+	}
+	if opts.Format == WikitextFormat {
+		params["prop"] = []string{"wikitext"}
+	}
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(params), &r)
+	if err != nil {
+		return "", err
+	}
+	if r.Parse == nil {
+		return "", invalidResponse()
+	}
+
+	var child *Section
+	if section != nil && !opts.Subsections && len(section.Children) > 0 {
+		child = section.Children[0]
+	}
+	switch opts.Format {
+	case WikitextFormat:
+		content := r.Parse.Wikitext
+		if child != nil {
+			offset := child.ByteOffset - section.ByteOffset
+			if offset > 0 && offset <= len(content) {
+				content = content[:offset]
+			}
+		}
+		return strings.TrimSpace(content), nil
+	case HtmlFormat:
+		return strings.TrimSpace(cutHtmlSection(r.Parse.Text, child)), nil
+	default:
+		return htmlToText(cutHtmlHeading(cutHtmlSection(r.Parse.Text, child), section)), nil
+	}
+}
+
+func cutHtmlSection(text string, child *Section) string {
+	if child == nil {
+		return text
+	}
+	index := strings.Index(text, `id="`+child.Anchor+`"`)
+	if index == -1 {
+		return text
+	}
+	start := strings.LastIndex(text[:index], "<h"+strconv.Itoa(child.Level))
+	if start == -1 {
+		return text
+	}
+	wrapper := strings.LastIndex(text[:start], `<div class="mw-heading`)
+	if wrapper != -1 && strings.Count(text[wrapper:start], ">") == 1 {
+		start = wrapper
+	}
+	return text[:start]
+}
+
+func cutHtmlHeading(text string, section *Section) string {
+	if section == nil {
+		return text
+	}
+	level := strconv.Itoa(section.Level)
+	start := strings.Index(text, "<h"+level)
+	if start == -1 {
+		return text
+	}
+	end := strings.Index(text[start:], "</h"+level+">")
+	if end == -1 {
+		return text
+	}
+	end += start + len("</h"+level+">")
+	wrapper := strings.LastIndex(text[:start], `<div class="mw-heading`)
+	if wrapper != -1 && strings.Count(text[wrapper:start], ">") == 1 && strings.HasPrefix(strings.TrimSpace(text[end:]), "</div>") {
+		start = wrapper
+		end += strings.Index(text[end:], "</div>") + len("</div>")
+	}
+	return text[:start] + text[end:]
+}
+
+func (tree *SectionTree) ByTitle(title string) *Section {
+	for _, section := range tree.sections {
+		if section.Line == title || htmlToText(section.Line) == title || section.Anchor == title {
+			return section
+		}
+	}
+	return nil
+}
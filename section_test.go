@@ -0,0 +1,145 @@
+package wikipedia
+
+import "errors"
+import "fmt"
+import "net/http"
+import "testing"
+
+func TestSectionTree(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("action") == "query" {
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A"}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"sections":[`+
+			`{"toclevel":1,"level":"2","line":"History","number":"1","index":"1","fromtitle":"A","byteoffset":120,"anchor":"History"},`+
+			`{"toclevel":2,"level":"3","line":"Early years","number":"1.1","index":"2","fromtitle":"A","byteoffset":340,"anchor":"Early_years"},`+
+			`{"toclevel":2,"level":"3","line":"Later years","number":"1.2","index":"3","fromtitle":"A","byteoffset":560,"anchor":"Later_years"},`+
+			`{"toclevel":1,"level":"2","line":"See also","number":"2","index":"4","fromtitle":"A","byteoffset":800,"anchor":"See_also"},`+
+			`{"toclevel":1,"level":"2","line":"From template","number":"3","index":"T-1","fromtitle":"Template:B","byteoffset":null,"anchor":"From_template"}]}}`)
+	})
+	tree, err := NewPage(w, "A").SectionTree()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting section tree %s", err))
+		return
+	}
+	if len(tree.Roots) != 3 || len(tree.All()) != 5 {
+		t.Error(fmt.Sprintf("got wrong tree shape %d roots, %d sections", len(tree.Roots), len(tree.All())))
+		return
+	}
+	history := tree.Roots[0]
+	if len(history.Children) != 2 || history.Children[1].Line != "Later years" {
+		t.Error("got wrong children")
+		return
+	}
+	section := tree.ByAnchor("Early_years")
+	if section == nil || section.Parent != history || section.Number != "1.1" {
+		t.Error("got wrong section by anchor")
+		return
+	}
+	if section.Level != 3 || section.Index != 2 || section.ByteOffset != 340 {
+		t.Error(fmt.Sprintf("got wrong section fields %+v", section))
+		return
+	}
+	section = tree.ByNumber("2")
+	if section == nil || section.Anchor != "See_also" || section.Parent != nil {
+		t.Error("got wrong section by number")
+		return
+	}
+	if section.Transcluded || section.FromTitle != "A" {
+		t.Error("expected section from the page itself")
+		return
+	}
+	section = tree.ByAnchor("From_template")
+	if section == nil || section.Transcluded == false || section.Index != 1 || section.FromTitle != "Template:B" {
+		t.Error(fmt.Sprintf("got wrong transcluded section %+v", section))
+		return
+	}
+	if tree.ByNumber("4") != nil {
+		t.Error("expected no section")
+		return
+	}
+}
+
+func newSectionTestWikipedia(t *testing.T) Wikipedia {
+	return newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("action") == "query":
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A"}]}}`)
+		case q.Get("prop") == "sections":
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"sections":[`+
+				`{"toclevel":1,"level":"2","line":"<i>History</i>","number":"1","index":"1","byteoffset":10,"anchor":"History"},`+
+				`{"toclevel":2,"level":"3","line":"Early years","number":"1.1","index":"2","byteoffset":39,"anchor":"Early_years"},`+
+				`{"toclevel":1,"level":"2","line":"From template","number":"2","index":"T-1","fromtitle":"Template:B","byteoffset":null,"anchor":"From_template"}]}}`)
+		case q.Get("section") != "1":
+			t.Error(fmt.Sprintf("unexpected section %s", q.Get("section")))
+		case q.Get("prop") == "wikitext":
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"wikitext":"== ''History'' ==\nIt began.\n\n=== Early years ===\nIt grew.\n"}}`)
+		default:
+			fmt.Fprint(rw, `{"parse":{"title":"A","pageid":1,"text":"<div class=\"mw-content-ltr mw-parser-output\"><div class=\"mw-heading mw-heading2\"><h2 id=\"History\"><i>History</i></h2></div>\n<p>It began.<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup>&#160;</p>\n<div class=\"mw-heading mw-heading3\"><h3 id=\"Early_years\">Early years</h3></div>\n<p>It grew.<br>Fast.</p></div>"}}`)
+		}
+	})
+}
+
+func TestSectionContentByTitle(t *testing.T) {
+	t.Parallel()
+	content, err := NewPage(newSectionTestWikipedia(t), "A").SectionContent("History")
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting section content %s", err))
+		return
+	}
+	if content != "It began." {
+		t.Error(fmt.Sprintf("got wrong section content %q", content))
+		return
+	}
+}
+
+func TestSectionContentTranscluded(t *testing.T) {
+	t.Parallel()
+	_, err := NewPage(newSectionTestWikipedia(t), "A").SectionContent("From template")
+	if errors.Is(err, ErrParameter) == false {
+		t.Error(fmt.Sprintf("Expected parameter error, got %v", err))
+		return
+	}
+}
+
+func TestReadSection(t *testing.T) {
+	t.Parallel()
+	page := NewPage(newSectionTestWikipedia(t), "A")
+	tree, err := page.SectionTree()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting section tree %s", err))
+		return
+	}
+	section := tree.ByAnchor("History")
+	tests := []struct {
+		opts     SectionOptions
+		expected string
+	}{
+		{SectionOptions{Format: PlainTextFormat, Subsections: true}, "It began.\n\nEarly years\n\nIt grew.\nFast."},
+		{SectionOptions{Format: WikitextFormat}, "== ''History'' ==\nIt began."},
+		{SectionOptions{Format: WikitextFormat, Subsections: true}, "== ''History'' ==\nIt began.\n\n=== Early years ===\nIt grew."},
+		{SectionOptions{Format: HtmlFormat}, "<div class=\"mw-content-ltr mw-parser-output\"><div class=\"mw-heading mw-heading2\"><h2 id=\"History\"><i>History</i></h2></div>\n<p>It began.<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup>&#160;</p>"},
+	}
+	for _, test := range tests {
+		content, err := page.ReadSection(section, test.opts)
+		if err != nil {
+			t.Error(fmt.Sprintf("error reading section %s", err))
+			return
+		}
+		if content != test.expected {
+			t.Error(fmt.Sprintf("got wrong content for %+v: %q", test.opts, content))
+		}
+	}
+}
+
+func TestHtmlToTextTable(t *testing.T) {
+	t.Parallel()
+	text := htmlToText(`<table><tr><th>Born</th><td>1815</td></tr><tr><th>Died</th><td>1852</td></tr></table>`)
+	if text != "Born\t1815\n\nDied\t1852" {
+		t.Error(fmt.Sprintf("got wrong table text %q", text))
+		return
+	}
+}
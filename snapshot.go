@@ -0,0 +1,184 @@
+package wikipedia
+
+import "context"
+import "fmt"
+import "strings"
+
+type PageSnapshot struct {
+	Id, Title, Url string
+	Extract, Intro string
+	Categories     []Category
+	Images         []Image
+	PageProps      map[string]string
+	LastRevision   Revision
+	Redirects      []Redirect
+	Disambiguation bool
+	withCategories bool
+	withImages     bool
+	summaryOnly    bool
+}
+
+type LoadOptions struct {
+	Categories, Images, SummaryOnly bool
+}
+
+func snapshotParams(opts LoadOptions) map[string][]string {
+	props := []string{"info", "pageprops", "extracts", "revisions"}
+	params := map[string][]string{
+		"inprop":      {"url"},
+		"explaintext": {""},
+		"rvprop":      {"ids|timestamp|user|comment"},
+		"format":      {"json"},
+		"action":      {"query"},
+	}
+	if opts.Categories {
+		props = append(props, "categories")
+		params["cllimit"] = []string{"max"}
+	}
+	if opts.Images {
+		props = append(props, "images")
+		params["imlimit"] = []string{"max"}
+	}
+	if opts.SummaryOnly {
+		params["exintro"] = []string{""}
+		params["exlimit"] = []string{"max"}
+	}
+	params["prop"] = []string{strings.Join(props, "|")}
+	return params
+}
+
+func intro(extract string) string {
+	index := strings.Index(extract, "\n== ")
+	if index == -1 {
+		return strings.TrimSpace(extract)
+	}
+	return strings.TrimSpace(extract[:index])
+}
+
+func (snapshot *PageSnapshot) merge(page *apiPage) {
+	snapshot.Id = page.id()
+	snapshot.Title = page.Title
+	if page.FullUrl != "" {
+		snapshot.Url = page.FullUrl
+	}
+	if page.Extract != "" {
+		if snapshot.summaryOnly {
+			snapshot.Intro = strings.TrimSpace(page.Extract)
+		} else {
+			snapshot.Extract = page.Extract
+			snapshot.Intro = intro(page.Extract)
+		}
+	}
+	for k, v := range page.PageProps {
+		snapshot.PageProps[k] = v
+	}
+	if page.isDisambiguation() {
+		snapshot.Disambiguation = true
+	}
+	if len(page.Revisions) > 0 {
+		snapshot.LastRevision = page.Revisions[0].revision()
+	}
+	for _, category := range page.Categories {
+		snapshot.Categories = append(snapshot.Categories, Category{Name: category.Title})
+	}
+	for _, image := range page.Images {
+		snapshot.Images = append(snapshot.Images, Image{Title: image.Title})
+	}
+}
+
+func newSnapshot(opts LoadOptions) *PageSnapshot {
+	return &PageSnapshot{
+		Categories:     make([]Category, 0),
+		Images:         make([]Image, 0),
+		PageProps:      make(map[string]string),
+		withCategories: opts.Categories,
+		withImages:     opts.Images,
+		summaryOnly:    opts.SummaryOnly,
+	}
+}
+
+func (page *PageClient) Load(opts LoadOptions) (*PageSnapshot, error) {
+	return page.LoadContext(context.Background(), opts)
+}
+
+func (page *PageClient) LoadContext(ctx context.Context, opts LoadOptions) (*PageSnapshot, error) {
+	if page.revision != 0 {
+		return nil, newError(ParameterError, fmt.Errorf("cannot load a snapshot of a page pinned to revision %d", page.revision))
+	}
+	k, v := page.queryParam()
+	snapshot := newSnapshot(opts)
+	params := map[string][]string{"continue": {""}}
+	for {
+		for k, v := range snapshotParams(opts) {
+			params[k] = v
+		}
+		params[k] = []string{v}
+		var r apiResponse
+		err := query(ctx, page.wikipedia, page.withRedirects(params), &r)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Redirects, err = resolveRedirects(r.Query, page.title)
+		if err != nil {
+			return nil, err
+		}
+		p, err := r.firstPage()
+		if err != nil {
+			return nil, err
+		}
+		snapshot.merge(p)
+		params = parseCont(&r)
+		if len(params) == 0 {
+			break
+		}
+	}
+	if opts.Images {
+		if err := page.resolveImages(ctx, snapshot.Images); err != nil {
+			return nil, err
+		}
+	}
+	page.mu.Lock()
+	page.snapshot = snapshot
+	page.mu.Unlock()
+	return snapshot, nil
+}
+
+func (page *PageClient) resolveImages(ctx context.Context, images []Image) error {
+	for start := 0; start < len(images); start += 50 {
+		batch := images[start:min(start+50, len(images))]
+		titles := make([]string, len(batch))
+		for i, image := range batch {
+			titles[i] = image.Title
+		}
+		var r apiResponse
+		err := query(ctx, page.wikipedia, map[string][]string{
+			"titles": {strings.Join(titles, "|")},
+			"prop":   {"imageinfo"},
+			"iiprop": {"url"},
+			"format": {"json"},
+			"action": {"query"},
+		}, &r)
+		if err != nil {
+			return err
+		}
+		info := make(map[string]apiImageInfo)
+		for _, p := range r.pages() {
+			if len(p.ImageInfo) > 0 {
+				info[p.Title] = p.ImageInfo[0]
+			}
+		}
+		for i := range batch {
+			if imageInfo, ok := info[batch[i].Title]; ok {
+				batch[i].Url = imageInfo.Url
+				batch[i].DescriptionUrl = imageInfo.DescriptionUrl
+			}
+		}
+	}
+	return nil
+}
+
+func (page *PageClient) cachedSnapshot() *PageSnapshot {
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	return page.snapshot
+}
@@ -0,0 +1,300 @@
+package wikipedia
+
+import "context"
+import "encoding/csv"
+import "encoding/json"
+import "encoding/xml"
+import "io"
+import "strconv"
+import "strings"
+
+type CellLink struct {
+	Text, Href, Title string
+}
+
+type TableCell struct {
+	Text             string
+	Header           bool
+	RowSpan, ColSpan int
+	Spanned          bool
+	Links            []CellLink
+}
+
+type Table struct {
+	Caption string
+	Headers [][]TableCell
+	Rows    [][]TableCell
+}
+
+type tableRow struct {
+	cells  []*TableCell
+	header bool
+}
+
+type tableBuilder struct {
+	wikitable bool
+	caption   *strings.Builder
+	rows      []*tableRow
+	cell      *TableCell
+	text      strings.Builder
+	link      *CellLink
+	thead     bool
+}
+
+func cellSpan(element xml.StartElement, name string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(attr(element, name)))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return min(n, 1000)
+}
+
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func hidden(element xml.StartElement) bool {
+	style := strings.ReplaceAll(attr(element, "style"), " ", "")
+	return skipped(element) || strings.Contains(style, "display:none")
+}
+
+func (b *tableBuilder) finishCell() {
+	if b.cell == nil {
+		return
+	}
+	b.cell.Text = cleanText(b.text.String())
+	b.cell = nil
+	b.text.Reset()
+}
+
+func (b *tableBuilder) table() Table {
+	grid := make([][]*TableCell, len(b.rows))
+	width := 0
+	for r, row := range b.rows {
+		c := 0
+		for _, cell := range row.cells {
+			for c < len(grid[r]) && grid[r][c] != nil {
+				c++
+			}
+			for dr := 0; dr < cell.RowSpan && r+dr < len(grid); dr++ {
+				for dc := 0; dc < cell.ColSpan; dc++ {
+					for len(grid[r+dr]) <= c+dc {
+						grid[r+dr] = append(grid[r+dr], nil)
+					}
+					if grid[r+dr][c+dc] != nil {
+						continue
+					}
+					placed := *cell
+					placed.Spanned = dr > 0 || dc > 0
+					grid[r+dr][c+dc] = &placed
+				}
+			}
+			c += cell.ColSpan
+		}
+		width = max(width, len(grid[r]))
+	}
+
+	table := Table{Headers: make([][]TableCell, 0), Rows: make([][]TableCell, 0)}
+	if b.caption != nil {
+		table.Caption = cleanText(b.caption.String())
+	}
+	body := false
+	for r, cells := range grid {
+		row := make([]TableCell, width)
+		header := len(cells) > 0
+		for c, cell := range cells {
+			if cell != nil {
+				row[c] = *cell
+				header = header && cell.Header
+			}
+		}
+		if !body && (b.rows[r].header || header) {
+			table.Headers = append(table.Headers, row)
+			continue
+		}
+		body = true
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+func parseTables(html string) []Table {
+	decoder := newHtmlDecoder(html)
+	tables := make([]Table, 0)
+	stack := make([]*tableBuilder, 0)
+	skip := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		var b *tableBuilder
+		if len(stack) > 0 {
+			b = stack[len(stack)-1]
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if skip > 0 || hidden(token) {
+				skip++
+				continue
+			}
+			switch token.Name.Local {
+			case "table":
+				classes := strings.Fields(attr(token, "class"))
+				wikitable := false
+				for _, class := range classes {
+					wikitable = wikitable || class == "wikitable"
+				}
+				stack = append(stack, &tableBuilder{wikitable: wikitable, rows: make([]*tableRow, 0)})
+			case "caption":
+				if b != nil {
+					b.caption = &strings.Builder{}
+				}
+			case "thead":
+				if b != nil {
+					b.thead = true
+				}
+			case "tr":
+				if b != nil {
+					b.finishCell()
+					b.rows = append(b.rows, &tableRow{cells: make([]*TableCell, 0), header: b.thead})
+				}
+			case "td", "th":
+				if b != nil {
+					b.finishCell()
+					if len(b.rows) == 0 {
+						b.rows = append(b.rows, &tableRow{cells: make([]*TableCell, 0), header: b.thead})
+					}
+					b.cell = &TableCell{
+						Header:  token.Name.Local == "th",
+						RowSpan: cellSpan(token, "rowspan"),
+						ColSpan: cellSpan(token, "colspan"),
+						Links:   make([]CellLink, 0),
+					}
+					row := b.rows[len(b.rows)-1]
+					row.cells = append(row.cells, b.cell)
+				}
+			case "a":
+				if b != nil && b.cell != nil {
+					b.link = &CellLink{Href: attr(token, "href"), Title: attr(token, "title")}
+				}
+			case "br":
+				if b != nil && b.cell != nil {
+					b.text.WriteString(" ")
+				}
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if b == nil {
+				continue
+			}
+			switch token.Name.Local {
+			case "table":
+				b.finishCell()
+				stack = stack[:len(stack)-1]
+				if b.wikitable {
+					tables = append(tables, b.table())
+				}
+			case "thead":
+				b.thead = false
+			case "td", "th":
+				b.finishCell()
+			case "a":
+				if b.link != nil && b.cell != nil {
+					b.link.Text = cleanText(b.link.Text)
+					b.cell.Links = append(b.cell.Links, *b.link)
+				}
+				b.link = nil
+			}
+		case xml.CharData:
+			if skip > 0 || b == nil {
+				continue
+			}
+			if b.cell != nil {
+				b.text.Write(token)
+				if b.link != nil {
+					b.link.Text += string(token)
+				}
+			} else if b.caption != nil && len(b.rows) == 0 {
+				b.caption.Write(token)
+			}
+		}
+	}
+	return tables
+}
+
+func (table *Table) columns() []string {
+	if len(table.Headers) == 0 {
+		return nil
+	}
+	header := table.Headers[len(table.Headers)-1]
+	columns := make([]string, len(header))
+	seen := make(map[string]int)
+	for i, cell := range header {
+		name := cell.Text
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name += "_" + strconv.Itoa(seen[name])
+		}
+		columns[i] = name
+	}
+	return columns
+}
+
+func (table *Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	for _, rows := range [][][]TableCell{table.Headers, table.Rows} {
+		for _, row := range rows {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = cell.Text
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (table *Table) WriteJSON(w io.Writer) error {
+	columns := table.columns()
+	records := make([]interface{}, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		if columns == nil {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = cell.Text
+			}
+			records = append(records, record)
+			continue
+		}
+		record := make(map[string]string, len(row))
+		for i, cell := range row {
+			if i < len(columns) {
+				record[columns[i]] = cell.Text
+			}
+		}
+		records = append(records, record)
+	}
+	return json.NewEncoder(w).Encode(records)
+}
+
+func (page *PageClient) Tables() ([]Table, error) {
+	return page.TablesContext(context.Background())
+}
+
+func (page *PageClient) TablesContext(ctx context.Context) ([]Table, error) {
+	html, err := page.HtmlContentContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseTables(html), nil
+}
@@ -0,0 +1,72 @@
+package wikipedia
+
+import "bytes"
+import "fmt"
+import "net/http"
+import "testing"
+
+const tableHtml = `<div class="mw-parser-output">
+<table class="infobox"><tr><th>Ignored</th></tr></table>
+<table class="wikitable sortable">
+<caption>Largest cities<sup class="reference"><a href="#cite_note-1">[1]</a></sup></caption>
+<tbody><tr><th rowspan="2">City</th><th colspan="2">Population</th></tr>
+<tr><th>2000</th><th>2010</th></tr>
+<tr><td><a href="/wiki/Springfield" title="Springfield">Springfield</a></td><td>30,000<br>(est.)</td><td rowspan="2">35,000</td></tr>
+<tr><td>Shelbyville<span style="display:none">hidden</span></td><td>20,000&#160;people</td></tr>
+</tbody></table>
+</div>`
+
+func TestTables(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(rw, `{"parse":{"title":"A","pageid":1,"text":%q}}`, tableHtml)
+	})
+	tables, err := NewPage(w, "A").Tables()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting tables %s", err))
+		return
+	}
+	if len(tables) != 1 {
+		t.Error(fmt.Sprintf("expected 1 table, got %d", len(tables)))
+		return
+	}
+	table := tables[0]
+	if table.Caption != "Largest cities" || len(table.Headers) != 2 || len(table.Rows) != 2 {
+		t.Error(fmt.Sprintf("got wrong table %+v", table))
+		return
+	}
+	if table.Headers[0][2].Text != "Population" || !table.Headers[0][2].Spanned || table.Headers[1][0].Text != "City" {
+		t.Error(fmt.Sprintf("got wrong headers %+v", table.Headers))
+		return
+	}
+	springfield := table.Rows[0][0]
+	if len(springfield.Links) != 1 || springfield.Links[0].Title != "Springfield" || springfield.Links[0].Href != "/wiki/Springfield" {
+		t.Error(fmt.Sprintf("got wrong links %+v", springfield.Links))
+		return
+	}
+	if table.Rows[1][2].Text != "35,000" || !table.Rows[1][2].Spanned || table.Rows[1][0].Text != "Shelbyville" {
+		t.Error(fmt.Sprintf("got wrong rows %+v", table.Rows))
+		return
+	}
+
+	var csv bytes.Buffer
+	if err := table.WriteCSV(&csv); err != nil {
+		t.Error(fmt.Sprintf("error writing csv %s", err))
+		return
+	}
+	expected := "City,Population,Population\nCity,2000,2010\nSpringfield,\"30,000 (est.)\",\"35,000\"\nShelbyville,\"20,000 people\",\"35,000\"\n"
+	if csv.String() != expected {
+		t.Error(fmt.Sprintf("got wrong csv %q", csv.String()))
+		return
+	}
+	var json bytes.Buffer
+	if err := table.WriteJSON(&json); err != nil {
+		t.Error(fmt.Sprintf("error writing json %s", err))
+		return
+	}
+	expected = `[{"2000":"30,000 (est.)","2010":"35,000","City":"Springfield"},{"2000":"20,000 people","2010":"35,000","City":"Shelbyville"}]` + "\n"
+	if json.String() != expected {
+		t.Error(fmt.Sprintf("got wrong json %q", json.String()))
+		return
+	}
+}
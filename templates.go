@@ -0,0 +1,109 @@
+package wikipedia
+
+import "fmt"
+import "strconv"
+import "strings"
+
+import "github.com/seppo0010/wikipedia-go/wikitext"
+
+var dateTemplates = map[string]bool{
+	"bda":                true,
+	"birth date":         true,
+	"birth date and age": true,
+	"death date":         true,
+	"death date and age": true,
+	"dob":                true,
+	"end date":           true,
+	"start date":         true,
+	"start date and age": true,
+}
+
+var listTemplates = map[string]string{
+	"bulleted list":   "\n",
+	"hlist":           ", ",
+	"plainlist":       "\n",
+	"ubl":             "\n",
+	"ublist":          "\n",
+	"unbulleted list": "\n",
+}
+
+var passTemplates = map[string]int{
+	"abbr":     1,
+	"big":      1,
+	"lang":     2,
+	"marriage": 1,
+	"nobr":     1,
+	"nowrap":   1,
+	"small":    1,
+}
+
+var rangeWords = map[string]bool{
+	"-":   true,
+	"–":   true,
+	"and": true,
+	"to":  true,
+}
+
+func templateText(nodes []wikitext.Node) string {
+	return wikitext.PlainTextWith(nodes, renderTemplate)
+}
+
+func positionalParams(template *wikitext.Template) []string {
+	params := make([]string, 0, len(template.Params))
+	for _, param := range template.Params {
+		if param.Positional {
+			params = append(params, templateText(param.Value))
+		}
+	}
+	return params
+}
+
+func renderDate(params []string) string {
+	parts := make([]int, 0, 3)
+	for _, param := range params {
+		n, err := strconv.Atoi(strings.TrimSpace(param))
+		if err != nil || len(parts) == 3 {
+			break
+		}
+		parts = append(parts, n)
+	}
+	switch len(parts) {
+	case 1:
+		return fmt.Sprintf("%04d", parts[0])
+	case 2:
+		return fmt.Sprintf("%04d-%02d", parts[0], parts[1])
+	case 3:
+		return fmt.Sprintf("%04d-%02d-%02d", parts[0], parts[1], parts[2])
+	}
+	return ""
+}
+
+func renderTemplate(template *wikitext.Template) string {
+	name := strings.ToLower(template.Name)
+	params := positionalParams(template)
+	switch {
+	case strings.HasPrefix(name, "formatnum:"):
+		return strings.TrimSpace(template.Name[len("formatnum:"):])
+	case dateTemplates[name]:
+		return renderDate(params)
+	case name == "convert" || name == "cvt":
+		if len(params) >= 4 && rangeWords[params[1]] {
+			return params[0] + "–" + params[2] + " " + params[3]
+		}
+		return strings.Join(params[:min(len(params), 2)], " ")
+	case name == "url":
+		if len(params) >= 2 {
+			return params[1]
+		}
+		if len(params) == 1 {
+			return params[0]
+		}
+	case listTemplates[name] != "":
+		return strings.Join(params, listTemplates[name])
+	case passTemplates[name] > 0:
+		if len(params) >= passTemplates[name] {
+			return params[passTemplates[name]-1]
+		}
+	}
+	return ""
+}
@@ -1,45 +1,199 @@
 package wikipedia
 
+import "compress/gzip"
+import "context"
 import "net/http"
 import "net/url"
 import "errors"
 import "fmt"
+import "io"
+import "iter"
 import "encoding/json"
+import "runtime"
+import "sort"
+import "strconv"
 import "strings"
+import "time"
 
 const LANGUAGE_URL_MARKER = "{language}"
 
+const LIBRARY_USER_AGENT = "wikipedia-go (https://github.com/seppo0010/wikipedia-go)"
+
 type Wikipedia interface {
 	Page(title string) Page
 	PageFromId(id string) Page
+	PageAtRevision(revid int64) Page
 	GetBaseUrl() string
 	SetBaseUrl(baseUrl string)
 	SetImagesResults(imagesResults string)
 	SetLinksResults(linksResults string)
 	SetCategoriesResults(categoriesResults string)
+	SetFollowRedirects(followRedirects bool)
 	PreLanguageUrl() string
 	PostLanguageUrl() string
 	Language() string
 	SearchResults() int
 	GetLanguages() (languages []Language, err error)
+	GetLanguagesContext(ctx context.Context) (languages []Language, err error)
 	Search(query string) (results []string, err error)
+	SearchContext(ctx context.Context, query string) (results []string, err error)
 	Geosearch(latitude float64, longitude float64, radius int) (results []string, err error)
+	GeosearchContext(ctx context.Context, latitude float64, longitude float64, radius int) (results []string, err error)
 	RandomCount(count uint) (results []string, err error)
+	RandomCountContext(ctx context.Context, count uint) (results []string, err error)
 	Random() (string, error)
+	RandomContext(ctx context.Context) (string, error)
+	Pages(titles ...string) (results []PageResult, err error)
+	PagesContext(ctx context.Context, titles ...string) (results []PageResult, err error)
+	Query(params map[string][]string) (result *QueryResult, err error)
+	QueryContext(ctx context.Context, params map[string][]string) (result *QueryResult, err error)
+	QueryAll(params map[string][]string) iter.Seq2[*QueryResult, error]
+	QueryAllContext(ctx context.Context, params map[string][]string) iter.Seq2[*QueryResult, error]
 	ImagesResults() string
 	LinksResults() string
 	CategoriesResults() string
+	FollowRedirects() bool
+	HttpClient() *http.Client
+	WarningHandler() func(Warning)
+	Cache() Cache
+	CacheRevalidation() time.Duration
+	RetryPolicy() RetryPolicy
+	Maxlag() int
+	Throttle(ctx context.Context) error
+	UserAgent() string
+	ApiUserAgent() string
+	AcceptGzip() bool
 }
 
 type WikipediaClient struct {
 	preLanguageUrl, postLanguageUrl, language      string
 	imagesResults, linksResults, categoriesResults string
 	searchResults                                  int
+	followRedirects                                bool
+	httpClient                                     *http.Client
+	warningHandler                                 func(Warning)
+	cache                                          Cache
+	cacheRevalidation                              time.Duration
+	cacheQueries                                   bool
+	retryPolicy                                    RetryPolicy
+	maxlag                                         int
+	rateLimiter                                    *rateLimiter
+	userAgent, apiUserAgent                        string
+	acceptGzip                                     bool
+}
+
+type Option func(*WikipediaClient)
+
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(w *WikipediaClient) {
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		w.httpClient = httpClient
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(w *WikipediaClient) {
+		httpClient := *w.httpClient
+		httpClient.Transport = transport
+		w.httpClient = &httpClient
+	}
+}
+
+func WithCache(cache Cache) Option {
+	return func(w *WikipediaClient) {
+		w.cache = newRevisionCache(cache)
+	}
+}
+
+func WithCacheRevalidation(interval time.Duration) Option {
+	return func(w *WikipediaClient) {
+		w.cacheRevalidation = interval
+	}
+}
+
+func WithQueryCache(cacheQueries bool) Option {
+	return func(w *WikipediaClient) {
+		w.cacheQueries = cacheQueries
+	}
+}
+
+func WithRetryPolicy(retryPolicy RetryPolicy) Option {
+	return func(w *WikipediaClient) {
+		w.retryPolicy = retryPolicy
+	}
+}
+
+func WithMaxlag(maxlag int) Option {
+	return func(w *WikipediaClient) {
+		w.maxlag = maxlag
+	}
+}
+
+func WithRateLimit(interval time.Duration) Option {
+	return func(w *WikipediaClient) {
+		w.rateLimiter = &rateLimiter{interval: interval}
+	}
+}
+
+func WithUserAgent(application, contact, version string) Option {
+	return func(w *WikipediaClient) {
+		w.userAgent = userAgent(application, contact, version)
+	}
+}
+
+func WithApiUserAgent(apiUserAgent string) Option {
+	return func(w *WikipediaClient) {
+		w.apiUserAgent = apiUserAgent
+	}
+}
+
+func WithGzip(acceptGzip bool) Option {
+	return func(w *WikipediaClient) {
+		w.acceptGzip = acceptGzip
+	}
+}
+
+func userAgent(application, contact, version string) string {
+	ua := application
+	if version != "" {
+		ua = fmt.Sprintf("%s/%s", ua, version)
+	}
+	if contact != "" {
+		ua = fmt.Sprintf("%s (%s)", ua, contact)
+	}
+	return fmt.Sprintf("%s %s %s", ua, LIBRARY_USER_AGENT, runtime.Version())
+}
+
+func WithWarningHandler(handler func(Warning)) Option {
+	return func(w *WikipediaClient) {
+		w.warningHandler = handler
+	}
 }
 
 const (
-	ParameterError = iota
-	ResponseError  = iota
+	ParameterError    = iota
+	ResponseError     = iota
+	NetworkError      = iota
+	DecodeError       = iota
+	ApiError          = iota
+	MissingPageError  = iota
+	InvalidTitleError = iota
+	RateLimitError    = iota
+	RedirectLoopError = iota
+)
+
+var (
+	ErrParameter    = &WikipediaError{Type: ParameterError}
+	ErrResponse     = &WikipediaError{Type: ResponseError}
+	ErrNetwork      = &WikipediaError{Type: NetworkError}
+	ErrDecode       = &WikipediaError{Type: DecodeError}
+	ErrApi          = &WikipediaError{Type: ApiError}
+	ErrMissingPage  = &WikipediaError{Type: MissingPageError}
+	ErrInvalidTitle = &WikipediaError{Type: InvalidTitleError}
+	ErrRateLimited  = &WikipediaError{Type: RateLimitError}
+	ErrRedirectLoop = &WikipediaError{Type: RedirectLoopError}
 )
 
 type WikipediaError struct {
@@ -52,22 +206,96 @@ func newError(t int, e error) *WikipediaError {
 }
 
 func (e *WikipediaError) Error() string {
+	var kind string
 	switch e.Type {
 	case ParameterError:
-		return fmt.Sprintf("parameter error: %s", e.Err.Error())
+		kind = "parameter error"
 	case ResponseError:
-		return fmt.Sprintf("response error: %s", e.Err.Error())
+		kind = "response error"
+	case NetworkError:
+		kind = "network error"
+	case DecodeError:
+		kind = "decode error"
+	case ApiError:
+		kind = "api error"
+	case MissingPageError:
+		kind = "missing page"
+	case InvalidTitleError:
+		kind = "invalid title"
+	case RateLimitError:
+		kind = "rate limited"
+	case RedirectLoopError:
+		kind = "redirect loop"
+	default:
+		kind = "unknown error"
+	}
+	if e.Err == nil {
+		return kind
+	}
+	return fmt.Sprintf("%s: %s", kind, e.Err.Error())
+}
+
+func (e *WikipediaError) Unwrap() error {
+	return e.Err
+}
+
+func (e *WikipediaError) Is(target error) bool {
+	t, ok := target.(*WikipediaError)
+	return ok && t.Err == nil && t.Type == e.Type
+}
+
+type MediaWikiError struct {
+	Code   string `json:"code"`
+	Info   string `json:"info"`
+	DocRef string `json:"docref"`
+}
+
+func (e *MediaWikiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Info)
+}
+
+func apiError(e *MediaWikiError) *WikipediaError {
+	switch e.Code {
+	case "missingtitle", "nosuchpageid", "nosuchrevid":
+		return newError(MissingPageError, e)
+	case "invalidtitle":
+		return newError(InvalidTitleError, e)
+	case "ratelimited":
+		return newError(RateLimitError, e)
 	default:
-		return fmt.Sprintf("unknown error: %s", e.Err.Error())
+		return newError(ApiError, e)
+	}
+}
+
+type Warning struct {
+	Module, Info string
+}
+
+func parseWarnings(warnings map[string]map[string]interface{}) []Warning {
+	modules := make([]string, 0, len(warnings))
+	for module := range warnings {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	results := make([]Warning, 0)
+	for _, module := range modules {
+		for _, key := range []string{"*", "warnings"} {
+			if info, ok := warnings[module][key].(string); ok {
+				for _, line := range strings.Split(info, "\n") {
+					results = append(results, Warning{Module: module, Info: line})
+				}
+			}
+		}
 	}
+	return results
 }
 
 type Language struct {
 	code, name string
 }
 
-func NewWikipedia() *WikipediaClient {
-	return &WikipediaClient{
+func NewWikipedia(options ...Option) *WikipediaClient {
+	w := &WikipediaClient{
 		preLanguageUrl:    "https://",
 		postLanguageUrl:   ".wikipedia.org/w/api.php",
 		language:          "en",
@@ -75,7 +303,18 @@ func NewWikipedia() *WikipediaClient {
 		imagesResults:     "max",
 		linksResults:      "max",
 		categoriesResults: "max",
+		followRedirects:   true,
+		httpClient:        http.DefaultClient,
+		cacheRevalidation: time.Minute,
+		retryPolicy:       DefaultRetryPolicy,
+		maxlag:            5,
+		userAgent:         LIBRARY_USER_AGENT,
+		acceptGzip:        true,
+	}
+	for _, option := range options {
+		option(w)
 	}
+	return w
 }
 
 func (w *WikipediaClient) Page(title string) Page {
@@ -86,6 +325,10 @@ func (w *WikipediaClient) PageFromId(id string) Page {
 	return NewPageFromId(w, id)
 }
 
+func (w *WikipediaClient) PageAtRevision(revid int64) Page {
+	return NewPageAtRevision(w, revid)
+}
+
 func (w *WikipediaClient) GetBaseUrl() string {
 	return fmt.Sprintf("%s%s%s", w.preLanguageUrl, w.language, w.postLanguageUrl)
 }
@@ -114,6 +357,50 @@ func (w *WikipediaClient) CategoriesResults() string {
 	return w.categoriesResults
 }
 
+func (w *WikipediaClient) FollowRedirects() bool {
+	return w.followRedirects
+}
+
+func (w *WikipediaClient) HttpClient() *http.Client {
+	return w.httpClient
+}
+
+func (w *WikipediaClient) WarningHandler() func(Warning) {
+	return w.warningHandler
+}
+
+func (w *WikipediaClient) Cache() Cache {
+	return w.cache
+}
+
+func (w *WikipediaClient) CacheRevalidation() time.Duration {
+	return w.cacheRevalidation
+}
+
+func (w *WikipediaClient) RetryPolicy() RetryPolicy {
+	return w.retryPolicy
+}
+
+func (w *WikipediaClient) Maxlag() int {
+	return w.maxlag
+}
+
+func (w *WikipediaClient) Throttle(ctx context.Context) error {
+	return w.rateLimiter.wait(ctx)
+}
+
+func (w *WikipediaClient) UserAgent() string {
+	return w.userAgent
+}
+
+func (w *WikipediaClient) ApiUserAgent() string {
+	return w.apiUserAgent
+}
+
+func (w *WikipediaClient) AcceptGzip() bool {
+	return w.acceptGzip
+}
+
 func (w *WikipediaClient) SetImagesResults(imagesResults string) {
 	w.imagesResults = imagesResults
 }
@@ -126,39 +413,167 @@ func (w *WikipediaClient) SetCategoriesResults(categoriesResults string) {
 	w.categoriesResults = categoriesResults
 }
 
-func query(w Wikipedia, q map[string][]string, v interface{}) error {
-	resp, err := http.Get(fmt.Sprintf("%s?%s", w.GetBaseUrl(), url.Values(q).Encode()))
+func (w *WikipediaClient) SetFollowRedirects(followRedirects bool) {
+	w.followRedirects = followRedirects
+}
+
+func fetch(ctx context.Context, w Wikipedia, q map[string][]string) ([]byte, error) {
+	params := make(url.Values, len(q)+1)
+	for k, v := range q {
+		params[k] = v
+	}
+	if params.Get("formatversion") == "" {
+		params.Set("formatversion", "2")
+	}
+	if maxlag := w.Maxlag(); maxlag > 0 {
+		params.Set("maxlag", strconv.Itoa(maxlag))
+	}
+	policy := w.RetryPolicy()
+	for attempt := 0; ; attempt++ {
+		err := w.Throttle(ctx)
+		if err != nil {
+			return nil, newError(NetworkError, err)
+		}
+		body, retry, retryAfter, err := fetchOnce(ctx, w, params)
+		if err == nil || !retry || attempt >= policy.MaxRetries {
+			return body, err
+		}
+		delay, ok := policy.delay(attempt, retryAfter)
+		if !ok {
+			if errors.Is(err, ErrRateLimited) {
+				return nil, err
+			}
+			return nil, newError(RateLimitError, fmt.Errorf("retry after %s exceeds maximum delay: %w", retryAfter, err))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, newError(NetworkError, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func fetchOnce(ctx context.Context, w Wikipedia, params url.Values) (body []byte, retry bool, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?%s", w.GetBaseUrl(), params.Encode()), nil)
 	if err != nil {
-		return newError(ResponseError, err)
+		return nil, false, 0, newError(ParameterError, err)
+	}
+	req.Header.Set("User-Agent", w.UserAgent())
+	if apiUserAgent := w.ApiUserAgent(); apiUserAgent != "" {
+		req.Header.Set("Api-User-Agent", apiUserAgent)
+	}
+	if w.AcceptGzip() {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	resp, err := w.HttpClient().Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, -1, newError(NetworkError, err)
 	}
 	defer resp.Body.Close()
 
-	err = json.NewDecoder(resp.Body).Decode(&v)
+	retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, true, retryAfter, newError(RateLimitError, errors.New(resp.Status))
+	}
+	if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
+		return nil, true, retryAfter, newError(ResponseError, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, false, 0, newError(ResponseError, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+
+	reader := resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, false, 0, newError(DecodeError, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	body, err = io.ReadAll(reader)
 	if err != nil {
-		return newError(ResponseError, err)
+		return nil, ctx.Err() == nil, -1, newError(NetworkError, err)
 	}
-	return nil
+	var envelope struct {
+		Error *MediaWikiError `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil && envelope.Error.Code == "maxlag" {
+		return nil, true, retryAfter, apiError(envelope.Error)
+	}
+	return body, false, 0, nil
 }
 
-func processResults(v interface{}, field string) ([]string, error) {
-	results := make([]string, 0)
-	gotResults := false
-	if r, ok := v.(map[string]interface{}); ok {
-		if query, ok := r["query"].(map[string]interface{}); ok {
-			if values, ok := query[field].([]interface{}); ok {
-				gotResults = true
-				for _, l := range values {
-					if lang, ok := l.(map[string]interface{}); ok {
-						if title, ok := lang["title"].(string); ok {
-							results = append(results, title)
-						}
-					}
-				}
-			}
+func parseRetryAfter(header string) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return -1
+}
+
+func query(ctx context.Context, w Wikipedia, q map[string][]string, v interface{}) error {
+	return queryWith(ctx, w, w.Cache(), q, v)
+}
+
+func queryWith(ctx context.Context, w Wikipedia, cache Cache, q map[string][]string, v interface{}) error {
+	if !cacheable(q) {
+		cache = nil
+	}
+	key := cacheKey(w.GetBaseUrl(), q)
+	var body []byte
+	cached := false
+	if cache != nil {
+		body, cached = cache.Get(key)
+		if revisions, ok := cache.(*revisionCache); ok && cached && !revisions.revalidate(ctx, w, key) {
+			cached = false
+		}
+	}
+	if !cached {
+		var err error
+		body, err = fetch(ctx, w, q)
+		if err != nil {
+			return err
 		}
 	}
-	if gotResults == false {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+
+	var envelope struct {
+		Error    *MediaWikiError                   `json:"error"`
+		Warnings map[string]map[string]interface{} `json:"warnings"`
+	}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return newError(DecodeError, err)
+	}
+	if handler := w.WarningHandler(); handler != nil {
+		for _, warning := range parseWarnings(envelope.Warnings) {
+			handler(warning)
+		}
+	}
+	if envelope.Error != nil {
+		return apiError(envelope.Error)
+	}
+	err = json.Unmarshal(body, &v)
+	if err != nil {
+		return newError(DecodeError, err)
+	}
+	if cache != nil && !cached {
+		cache.Set(key, body)
+	}
+	return nil
+}
+
+func processResults(r *apiResponse, values func(*apiQuery) []apiTitle) ([]string, error) {
+	if r.Query == nil || values(r.Query) == nil {
+		return nil, invalidResponse()
+	}
+	results := make([]string, 0)
+	for _, value := range values(r.Query) {
+		results = append(results, value.Title)
 	}
 	return results, nil
 }
@@ -180,57 +595,55 @@ func (w *WikipediaClient) SearchResults() int {
 }
 
 func (w *WikipediaClient) GetLanguages() ([]Language, error) {
-	var f interface{}
-	err := query(w, map[string][]string{
+	return w.GetLanguagesContext(context.Background())
+}
+
+func (w *WikipediaClient) GetLanguagesContext(ctx context.Context) ([]Language, error) {
+	var r apiResponse
+	err := query(ctx, w, map[string][]string{
 		"meta":   {"siteinfo"},
 		"siprop": {"languages"},
 		"format": {"json"},
 		"action": {"query"},
-	}, &f)
+	}, &r)
 	if err != nil {
 		return nil, err
 	}
-	gotLangs := false
-	languages := make([]Language, 0)
-	if r, ok := f.(map[string]interface{}); ok {
-		if query, ok := r["query"].(map[string]interface{}); ok {
-			if langs, ok := query["languages"].([]interface{}); ok {
-				gotLangs = true
-				for _, l := range langs {
-					if lang, ok := l.(map[string]interface{}); ok {
-						if code, ok := lang["code"].(string); ok {
-							if name, ok := lang["*"].(string); ok {
-								languages = append(languages, Language{code, name})
-							}
-						}
-					}
-				}
-			}
-		}
+	if r.Query == nil || r.Query.Languages == nil {
+		return nil, invalidResponse()
 	}
-	if gotLangs == false {
-		return nil, newError(ResponseError, errors.New("invalid json response"))
+	languages := make([]Language, 0)
+	for _, lang := range r.Query.Languages {
+		languages = append(languages, Language{lang.Code, lang.Name})
 	}
 	return languages, nil
 }
 
 func (w *WikipediaClient) Search(q string) ([]string, error) {
-	var f interface{}
-	err := query(w, map[string][]string{
+	return w.SearchContext(context.Background(), q)
+}
+
+func (w *WikipediaClient) SearchContext(ctx context.Context, q string) ([]string, error) {
+	var r apiResponse
+	err := query(ctx, w, map[string][]string{
 		"list":     {"search"},
 		"srpop":    {""},
 		"srlimit":  {fmt.Sprintf("%d", w.searchResults)},
 		"srsearch": {q},
 		"format":   {"json"},
 		"action":   {"query"},
-	}, &f)
+	}, &r)
 	if err != nil {
 		return nil, err
 	}
-	return processResults(f, "search")
+	return processResults(&r, func(q *apiQuery) []apiTitle { return q.Search })
 }
 
 func (w *WikipediaClient) Geosearch(latitude float64, longitude float64, radius int) ([]string, error) {
+	return w.GeosearchContext(context.Background(), latitude, longitude, radius)
+}
+
+func (w *WikipediaClient) GeosearchContext(ctx context.Context, latitude float64, longitude float64, radius int) ([]string, error) {
 	if latitude < -90.0 || latitude > 90.0 {
 		return nil, newError(ParameterError, errors.New("invalid latitude"))
 	}
@@ -240,38 +653,46 @@ func (w *WikipediaClient) Geosearch(latitude float64, longitude float64, radius
 	if radius < -10 || radius > 10000 {
 		return nil, newError(ParameterError, errors.New("invalid radius"))
 	}
-	var f interface{}
-	err := query(w, map[string][]string{
+	var r apiResponse
+	err := query(ctx, w, map[string][]string{
 		"list":     {"geosearch"},
 		"gsradius": {fmt.Sprintf("%d", radius)},
 		"gscoord":  {fmt.Sprintf("%f|%f", latitude, longitude)},
 		"gslimit":  {fmt.Sprintf("%d", w.searchResults)},
 		"format":   {"json"},
 		"action":   {"query"},
-	}, &f)
+	}, &r)
 	if err != nil {
 		return nil, err
 	}
-	return processResults(f, "geosearch")
+	return processResults(&r, func(q *apiQuery) []apiTitle { return q.Geosearch })
 }
 
 func (w *WikipediaClient) RandomCount(count uint) ([]string, error) {
-	var f interface{}
-	err := query(w, map[string][]string{
+	return w.RandomCountContext(context.Background(), count)
+}
+
+func (w *WikipediaClient) RandomCountContext(ctx context.Context, count uint) ([]string, error) {
+	var r apiResponse
+	err := query(ctx, w, map[string][]string{
 		"list":        {"random"},
 		"rnnamespace": {"0"},
 		"rnlimit":     {fmt.Sprintf("%d", count)},
 		"format":      {"json"},
 		"action":      {"query"},
-	}, &f)
+	}, &r)
 	if err != nil {
 		return nil, err
 	}
-	return processResults(f, "random")
+	return processResults(&r, func(q *apiQuery) []apiTitle { return q.Random })
 }
 
 func (w *WikipediaClient) Random() (string, error) {
-	results, err := w.RandomCount(1)
+	return w.RandomContext(context.Background())
+}
+
+func (w *WikipediaClient) RandomContext(ctx context.Context) (string, error) {
+	results, err := w.RandomCountContext(ctx, 1)
 	if err != nil {
 		return "", err
 	}
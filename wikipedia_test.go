@@ -1,5 +1,13 @@
 package wikipedia
 
+import "compress/gzip"
+import "context"
+import "errors"
+import "fmt"
+import "io"
+import "net/http"
+import "net/http/httptest"
+import "strings"
 import "testing"
 
 func contains(s []string, e string) bool {
@@ -11,6 +19,14 @@ func contains(s []string, e string) bool {
 	return false
 }
 
+func newTestWikipedia(t *testing.T, handler http.HandlerFunc, options ...Option) *WikipediaClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	w := NewWikipedia(options...)
+	w.SetBaseUrl(server.URL)
+	return w
+}
+
 func TestGetLanguages(t *testing.T) {
 	t.Parallel()
 	w := NewWikipedia()
@@ -129,3 +145,197 @@ func TestRandomCount(t *testing.T) {
 		return
 	}
 }
+
+func TestSearchContextCanceled(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent with a canceled context")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := w.SearchContext(ctx, "hello world")
+	if err == nil {
+		t.Error("Expected error")
+		return
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+	body     string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(rt.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestWithTransport(t *testing.T) {
+	t.Parallel()
+	rt := &recordingTransport{body: `{"query":{"search":[{"title":"Hello"}],"pages":[{"pageid":1,"title":"Hello","extract":"Hello there"}]}}`}
+	w := NewWikipedia(WithTransport(rt))
+	if w.HttpClient() == http.DefaultClient {
+		t.Error("Expected a dedicated http client")
+		return
+	}
+	results, err := w.Search("hello")
+	if err != nil {
+		t.Error("Got error")
+		return
+	}
+	if contains(results, "Hello") == false {
+		t.Error("Expected results to contain Hello")
+		return
+	}
+	summary, err := w.Page("Hello").Summary()
+	if err != nil {
+		t.Error("Got error")
+		return
+	}
+	if summary != "Hello there" {
+		t.Error("Got wrong summary")
+		return
+	}
+	if len(rt.requests) != 2 {
+		t.Error("Expected every request to go through the transport")
+		return
+	}
+}
+
+func TestWithHttpClient(t *testing.T) {
+	t.Parallel()
+	httpClient := &http.Client{}
+	w := NewWikipedia(WithHttpClient(httpClient))
+	if w.HttpClient() != httpClient {
+		t.Error("Expected the configured http client")
+		return
+	}
+	w = NewWikipedia(WithHttpClient(nil))
+	if w.HttpClient() != http.DefaultClient {
+		t.Error("Expected a nil http client to fall back to the default client")
+		return
+	}
+	rt := &recordingTransport{body: `{"query":{"search":[{"title":"Hello"}]}}`}
+	w = NewWikipedia(WithHttpClient(nil), WithTransport(rt))
+	if _, err := w.Search("hello"); err != nil || len(rt.requests) != 1 {
+		t.Error("Expected the transport to be used after a nil http client")
+		return
+	}
+}
+
+func TestErrorMissingPage(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"ns":0,"title":"Nope","missing":true}]}}`)
+	})
+	_, err := w.Page("Nope").Id()
+	if errors.Is(err, ErrMissingPage) == false {
+		t.Error(fmt.Sprintf("Expected missing page error, got %v", err))
+		return
+	}
+	if errors.Is(err, ErrInvalidTitle) {
+		t.Error("Did not expect invalid title error")
+		return
+	}
+}
+
+func TestErrorInvalidTitle(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"title":"<","invalidreason":"The requested page title contains invalid characters: \"<\".","invalid":true}]}}`)
+	})
+	_, err := w.Page("<").Content()
+	if errors.Is(err, ErrInvalidTitle) == false {
+		t.Error(fmt.Sprintf("Expected invalid title error, got %v", err))
+		return
+	}
+}
+
+func TestErrorApi(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"error":{"code":"badvalue","info":"Unrecognized value for parameter \"list\": nope."}}`)
+	})
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrApi) == false {
+		t.Error(fmt.Sprintf("Expected api error, got %v", err))
+		return
+	}
+	var mwErr *MediaWikiError
+	if errors.As(err, &mwErr) == false || mwErr.Code != "badvalue" {
+		t.Error("Expected MediaWikiError with code badvalue")
+		return
+	}
+}
+
+func TestErrorRateLimited(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}, WithRetryPolicy(RetryPolicy{}))
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrRateLimited) == false {
+		t.Error(fmt.Sprintf("Expected rate limit error, got %v", err))
+		return
+	}
+}
+
+func TestErrorDecode(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `<html>`)
+	})
+	_, err := w.Search("hello")
+	if errors.Is(err, ErrDecode) == false {
+		t.Error(fmt.Sprintf("Expected decode error, got %v", err))
+		return
+	}
+}
+
+func TestErrorNetwork(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewWikipedia().SearchContext(ctx, "hello")
+	if errors.Is(err, ErrNetwork) == false {
+		t.Error(fmt.Sprintf("Expected network error, got %v", err))
+		return
+	}
+	if errors.Is(err, context.Canceled) == false {
+		t.Error("Expected error to wrap context.Canceled")
+		return
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("User-Agent"), "MyBot/1.2 (https://example.org/mybot; mybot@example.org) wikipedia-go") == false {
+			t.Error(fmt.Sprintf("Got wrong User-Agent %s", r.Header.Get("User-Agent")))
+		}
+		if r.Header.Get("Api-User-Agent") != "MyBot/1.2" {
+			t.Error("Got wrong Api-User-Agent")
+		}
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Error("Expected gzip to be accepted")
+		}
+		rw.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(rw)
+		defer gz.Close()
+		fmt.Fprint(gz, `{"query":{"search":[{"title":"Hello"}]}}`)
+	}, WithUserAgent("MyBot", "https://example.org/mybot; mybot@example.org", "1.2"), WithApiUserAgent("MyBot/1.2"))
+	results, err := w.Search("hello")
+	if err != nil {
+		t.Error(fmt.Sprintf("Got error %s", err))
+		return
+	}
+	if contains(results, "Hello") == false {
+		t.Error("Expected results to contain Hello")
+		return
+	}
+}
@@ -0,0 +1,79 @@
+package wikipedia
+
+import "context"
+import "strconv"
+
+import "github.com/seppo0010/wikipedia-go/wikitext"
+
+type Wikitext struct {
+	Content      string
+	RevisionId   int64
+	ContentModel string
+}
+
+func (page *PageClient) requestWikitext(ctx context.Context, section string) (*Wikitext, error) {
+	k, v := page.queryParam()
+	params := map[string][]string{
+		"prop":    {"revisions"},
+		"rvprop":  {"content|ids|contentmodel"},
+		"rvslots": {"main"},
+		"format":  {"json"},
+		"action":  {"query"},
+		k:         {v},
+	}
+	if section != "" {
+		params["rvsection"] = []string{section}
+	}
+	var r apiResponse
+	err := query(ctx, page.wikipedia, page.withRedirects(params), &r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveRedirects(r.Query, page.title); err != nil {
+		return nil, err
+	}
+	p, err := r.firstPage()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Revisions) == 0 {
+		return nil, invalidResponse()
+	}
+	slot, ok := p.Revisions[0].Slots["main"]
+	if !ok {
+		return nil, invalidResponse()
+	}
+	return &Wikitext{
+		Content:      slot.Content,
+		RevisionId:   p.Revisions[0].RevId,
+		ContentModel: slot.ContentModel,
+	}, nil
+}
+
+func (page *PageClient) Wikitext() (*Wikitext, error) {
+	return page.WikitextContext(context.Background())
+}
+
+func (page *PageClient) WikitextContext(ctx context.Context) (*Wikitext, error) {
+	return page.requestWikitext(ctx, "")
+}
+
+func (page *PageClient) SectionWikitext(index int) (*Wikitext, error) {
+	return page.SectionWikitextContext(context.Background(), index)
+}
+
+func (page *PageClient) SectionWikitextContext(ctx context.Context, index int) (*Wikitext, error) {
+	return page.requestWikitext(ctx, strconv.Itoa(index))
+}
+
+func (page *PageClient) ParseWikitext() (*wikitext.Document, error) {
+	return page.ParseWikitextContext(context.Background())
+}
+
+func (page *PageClient) ParseWikitextContext(ctx context.Context) (*wikitext.Document, error) {
+	source, err := page.WikitextContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wikitext.Parse(source.Content), nil
+}
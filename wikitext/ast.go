@@ -0,0 +1,177 @@
+package wikitext
+
+type Pos struct {
+	Offset, Line, Column int
+}
+
+type Node interface {
+	Pos() Pos
+	End() Pos
+}
+
+type span struct {
+	pos, end Pos
+}
+
+func (s span) Pos() Pos {
+	return s.pos
+}
+
+func (s span) End() Pos {
+	return s.end
+}
+
+type Document struct {
+	span
+	Source string
+	Nodes  []Node
+}
+
+type Text struct {
+	span
+	Value string
+}
+
+type Comment struct {
+	span
+	Value string
+}
+
+type Heading struct {
+	span
+	Level int
+	Nodes []Node
+}
+
+type Template struct {
+	span
+	Name   string
+	Params []*Param
+}
+
+type Param struct {
+	span
+	Name       string
+	Positional bool
+	Value      []Node
+	Raw        string
+}
+
+type Link struct {
+	span
+	Target string
+	Nodes  []Node
+}
+
+type ExternalLink struct {
+	span
+	Url   string
+	Nodes []Node
+}
+
+type Tag struct {
+	span
+	Name        string
+	Attrs       map[string]string
+	SelfClosing bool
+	Nodes       []Node
+}
+
+type List struct {
+	span
+	Items []*ListItem
+}
+
+type ListItem struct {
+	span
+	Prefix string
+	Nodes  []Node
+}
+
+type Table struct {
+	span
+	Attrs   string
+	Caption []Node
+	Rows    []*TableRow
+}
+
+type TableRow struct {
+	span
+	Attrs string
+	Cells []*TableCell
+}
+
+type TableCell struct {
+	span
+	Header bool
+	Attrs  string
+	Nodes  []Node
+}
+
+func (d *Document) Raw(node Node) string {
+	return d.Source[node.Pos().Offset:node.End().Offset]
+}
+
+func (t *Template) Param(name string) *Param {
+	var found *Param
+	for _, param := range t.Params {
+		if param.Name == name {
+			found = param
+		}
+	}
+	return found
+}
+
+func children(node Node) []Node {
+	switch node := node.(type) {
+	case *Document:
+		return node.Nodes
+	case *Heading:
+		return node.Nodes
+	case *Template:
+		nodes := make([]Node, 0, len(node.Params))
+		for _, param := range node.Params {
+			nodes = append(nodes, param)
+		}
+		return nodes
+	case *Param:
+		return node.Value
+	case *Link:
+		return node.Nodes
+	case *ExternalLink:
+		return node.Nodes
+	case *Tag:
+		return node.Nodes
+	case *List:
+		nodes := make([]Node, 0, len(node.Items))
+		for _, item := range node.Items {
+			nodes = append(nodes, item)
+		}
+		return nodes
+	case *ListItem:
+		return node.Nodes
+	case *Table:
+		nodes := append(make([]Node, 0, len(node.Caption)+len(node.Rows)), node.Caption...)
+		for _, row := range node.Rows {
+			nodes = append(nodes, row)
+		}
+		return nodes
+	case *TableRow:
+		nodes := make([]Node, 0, len(node.Cells))
+		for _, cell := range node.Cells {
+			nodes = append(nodes, cell)
+		}
+		return nodes
+	case *TableCell:
+		return node.Nodes
+	}
+	return nil
+}
+
+func Inspect(nodes []Node, f func(Node) bool) {
+	for _, node := range nodes {
+		if f(node) {
+			Inspect(children(node), f)
+		}
+	}
+}
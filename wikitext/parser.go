@@ -0,0 +1,548 @@
+package wikitext
+
+import "regexp"
+import "sort"
+import "strconv"
+import "strings"
+
+var tagOpen = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9]*)((?:\s[^<>]*?)?)(/?)>`)
+var tagAttr = regexp.MustCompile(`([\w:.-]+)\s*(?:=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+var urlScheme = regexp.MustCompile(`^(?i)(https?:|ftp:|mailto:|//)`)
+
+var knownTags = map[string]bool{
+	"abbr": true, "b": true, "big": true, "blockquote": true, "br": true,
+	"categorytree": true, "ce": true, "center": true, "chem": true, "cite": true,
+	"code": true, "dd": true, "del": true, "div": true, "dl": true, "dt": true,
+	"em": true, "font": true, "gallery": true, "graph": true, "hiero": true,
+	"hr": true, "i": true, "imagemap": true, "includeonly": true, "indicator": true,
+	"inputbox": true, "ins": true, "kbd": true, "li": true, "mapframe": true,
+	"maplink": true, "math": true, "noinclude": true, "nowiki": true, "ol": true,
+	"onlyinclude": true, "p": true, "poem": true, "pre": true, "q": true,
+	"ref": true, "references": true, "s": true, "samp": true, "score": true,
+	"section": true, "small": true, "source": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "syntaxhighlight": true, "templatedata": true,
+	"timeline": true, "tt": true, "u": true, "ul": true, "var": true, "wbr": true,
+}
+
+var voidTags = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"wbr": true,
+}
+
+var rawTags = map[string]bool{
+	"categorytree": true, "ce": true, "chem": true, "gallery": true,
+	"graph": true, "hiero": true, "imagemap": true, "inputbox": true,
+	"mapframe": true, "maplink": true, "math": true, "nowiki": true,
+	"pre": true, "score": true, "source": true, "syntaxhighlight": true,
+	"templatedata": true, "timeline": true,
+}
+
+const listPrefixes = "*#:;"
+
+type parser struct {
+	src      string
+	pos      int
+	lines    []int
+	memo     map[memoKey]memoEntry
+	scans    map[scanKey]scanEntry
+	began    []bool
+	unclosed map[memoKey]bool
+	lists    map[memoKey]*List
+	items    map[*List]*listItems
+}
+
+type listItems struct {
+	item *ListItem
+	next *listItems
+}
+
+type scanKey struct {
+	offset, limit int
+	terminators   string
+}
+
+type scanEntry struct {
+	nodes []Node
+	end   int
+}
+
+type memoKey struct {
+	offset, limit int
+}
+
+type memoEntry struct {
+	node Node
+	end  int
+}
+
+func Parse(src string) *Document {
+	p := &parser{
+		src:      src,
+		lines:    []int{0},
+		memo:     make(map[memoKey]memoEntry),
+		scans:    make(map[scanKey]scanEntry),
+		began:    make([]bool, len(src)+1),
+		unclosed: make(map[memoKey]bool),
+		lists:    make(map[memoKey]*List),
+		items:    make(map[*List]*listItems),
+	}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+	nodes := p.parseNodes(len(src), nil)
+	p.fillLists(nodes)
+	return &Document{span: p.span(0, len(src)), Source: src, Nodes: nodes}
+}
+
+func (p *parser) position(offset int) Pos {
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > offset }) - 1
+	return Pos{Offset: offset, Line: line + 1, Column: offset - p.lines[line] + 1}
+}
+
+func (p *parser) span(start, end int) span {
+	return span{pos: p.position(start), end: p.position(end)}
+}
+
+func (p *parser) text(start, end int) *Text {
+	return &Text{span: p.span(start, end), Value: p.src[start:end]}
+}
+
+func (p *parser) has(limit int, prefix string) bool {
+	return strings.HasPrefix(p.src[p.pos:limit], prefix)
+}
+
+func (p *parser) lineEnd(limit int) int {
+	end := strings.IndexByte(p.src[p.pos:limit], '\n')
+	if end == -1 {
+		return limit
+	}
+	return p.pos + end
+}
+
+func (p *parser) parseNodes(limit int, terminators []string) []Node {
+	nodes := make([]Node, 0)
+	begin, textStart := p.pos, p.pos
+	key := scanKey{limit: limit, terminators: strings.Join(terminators, "\x00")}
+	for p.pos < limit {
+		if key.offset = p.pos; p.began[p.pos] {
+			if scan, ok := p.scans[key]; ok {
+				if first, ok := firstText(scan.nodes); ok && p.pos > textStart {
+					nodes = append(nodes, p.text(textStart, first.End().Offset))
+					nodes = append(nodes, scan.nodes[1:]...)
+				} else {
+					if p.pos > textStart {
+						nodes = append(nodes, p.text(textStart, p.pos))
+					}
+					nodes = append(nodes, scan.nodes...)
+				}
+				p.pos = scan.end
+				textStart = p.pos
+				break
+			}
+		}
+		stop := false
+		for _, terminator := range terminators {
+			if p.has(limit, terminator) {
+				stop = true
+				break
+			}
+		}
+		if stop {
+			break
+		}
+		start := p.pos
+		var node Node
+		if p.pos == 0 || p.src[p.pos-1] == '\n' {
+			node = p.parseBlock(limit)
+		}
+		if node == nil {
+			node = p.parseInline(limit)
+		}
+		if node == nil {
+			p.pos++
+			continue
+		}
+		if start > textStart {
+			nodes = append(nodes, p.text(textStart, start))
+		}
+		nodes = append(nodes, node)
+		textStart = p.pos
+	}
+	if p.pos > textStart {
+		nodes = append(nodes, p.text(textStart, p.pos))
+	}
+	key.offset = begin
+	p.scans[key] = scanEntry{nodes: nodes, end: p.pos}
+	p.began[begin] = true
+	return nodes
+}
+
+func (p *parser) parseBlock(limit int) Node {
+	switch {
+	case p.has(limit, "="):
+		return p.parseHeading(limit)
+	case p.has(limit, "{|"):
+		return p.parseTable(limit)
+	case strings.IndexByte(listPrefixes, p.src[p.pos]) != -1:
+		return p.parseList(limit)
+	}
+	return nil
+}
+
+func (p *parser) parseInline(limit int) Node {
+	switch {
+	case p.has(limit, "<!--"):
+		return p.parseComment(limit)
+	case p.has(limit, "{{{"):
+		return p.parseArgument(limit)
+	case p.has(limit, "{{"):
+		return p.memoized(limit, p.parseTemplate)
+	case p.has(limit, "[["):
+		return p.memoized(limit, p.parseLink)
+	case p.has(limit, "["):
+		return p.memoized(limit, p.parseExternalLink)
+	case p.has(limit, "<"):
+		return p.memoized(limit, p.parseTag)
+	}
+	return nil
+}
+
+func (p *parser) memoized(limit int, parse func(int) Node) Node {
+	key := memoKey{offset: p.pos, limit: limit}
+	if entry, ok := p.memo[key]; ok {
+		p.pos = entry.end
+		return entry.node
+	}
+	node := parse(limit)
+	p.memo[key] = memoEntry{node: node, end: p.pos}
+	return node
+}
+
+func (p *parser) parseHeading(limit int) Node {
+	start, end := p.pos, p.lineEnd(limit)
+	line := strings.TrimRight(p.src[start:end], " \t\r")
+	leading := len(line) - len(strings.TrimLeft(line, "="))
+	trailing := len(line) - len(strings.TrimRight(line, "="))
+	level := min(leading, trailing, 6)
+	if level == 0 || len(line) <= 2*level {
+		return nil
+	}
+	p.pos = start + level
+	nodes := p.parseNodes(start+len(line)-level, nil)
+	p.pos = end
+	return &Heading{span: p.span(start, end), Level: level, Nodes: nodes}
+}
+
+func (p *parser) parseComment(limit int) Node {
+	start := p.pos
+	end := strings.Index(p.src[start+4:limit], "-->")
+	if end == -1 {
+		p.pos = limit
+		return &Comment{span: p.span(start, limit), Value: p.src[start+4 : limit]}
+	}
+	p.pos = start + 4 + end + 3
+	return &Comment{span: p.span(start, p.pos), Value: p.src[start+4 : start+4+end]}
+}
+
+func (p *parser) parseArgument(limit int) Node {
+	start := p.pos
+	end := strings.Index(p.src[start+3:limit], "}}}")
+	if end == -1 {
+		return nil
+	}
+	p.pos = start + 3 + end + 3
+	return p.text(start, p.pos)
+}
+
+func (p *parser) raw(nodes []Node) string {
+	var raw strings.Builder
+	for _, node := range nodes {
+		if _, ok := node.(*Comment); !ok {
+			raw.WriteString(p.src[node.Pos().Offset:node.End().Offset])
+		}
+	}
+	return raw.String()
+}
+
+func (p *parser) parseTemplate(limit int) Node {
+	start := p.pos
+	p.pos += 2
+	name := p.raw(p.parseNodes(limit, []string{"|", "}}"}))
+	template := &Template{
+		Name:   strings.TrimSpace(strings.ReplaceAll(name, "_", " ")),
+		Params: make([]*Param, 0),
+	}
+	index := 0
+	paramStarts := make([]int, 0)
+	unclosed := false
+	for p.has(limit, "|") {
+		p.pos++
+		paramStart := p.pos
+		if p.unclosed[memoKey{offset: paramStart, limit: limit}] {
+			unclosed = true
+			break
+		}
+		paramStarts = append(paramStarts, paramStart)
+		value := p.parseNodes(limit, []string{"|", "}}"})
+		param := &Param{span: p.span(paramStart, p.pos), Value: value}
+		if text, ok := firstText(value); ok && strings.Contains(text.Value, "=") {
+			split := strings.Index(text.Value, "=")
+			param.Name = strings.TrimSpace(text.Value[:split])
+			valueStart := text.Pos().Offset + split + 1
+			param.Value = value[1:]
+			if valueStart < text.End().Offset {
+				param.Value = append([]Node{p.text(valueStart, text.End().Offset)}, param.Value...)
+			}
+			param.Raw = strings.TrimSpace(p.src[valueStart:p.pos])
+		} else {
+			index++
+			param.Name = strconv.Itoa(index)
+			param.Positional = true
+			param.Raw = p.src[paramStart:p.pos]
+		}
+		template.Params = append(template.Params, param)
+	}
+	if unclosed || !p.has(limit, "}}") {
+		for _, paramStart := range paramStarts {
+			p.unclosed[memoKey{offset: paramStart, limit: limit}] = true
+		}
+		p.pos = start
+		return nil
+	}
+	p.pos += 2
+	template.span = p.span(start, p.pos)
+	return template
+}
+
+func firstText(nodes []Node) (*Text, bool) {
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	text, ok := nodes[0].(*Text)
+	return text, ok
+}
+
+func (p *parser) parseLink(limit int) Node {
+	start := p.pos
+	p.pos += 2
+	target := p.raw(p.parseNodes(limit, []string{"|", "]]", "\n"}))
+	link := &Link{Target: strings.TrimSpace(target), Nodes: make([]Node, 0)}
+	if p.has(limit, "|") {
+		p.pos++
+		link.Nodes = p.parseNodes(limit, []string{"]]"})
+	}
+	if !p.has(limit, "]]") || link.Target == "" {
+		p.pos = start
+		return nil
+	}
+	p.pos += 2
+	link.span = p.span(start, p.pos)
+	return link
+}
+
+func (p *parser) parseExternalLink(limit int) Node {
+	start := p.pos
+	if !urlScheme.MatchString(p.src[start+1 : limit]) {
+		return nil
+	}
+	end := strings.IndexAny(p.src[start+1:limit], " \t\n]")
+	if end == -1 {
+		return nil
+	}
+	link := &ExternalLink{Url: p.src[start+1 : start+1+end], Nodes: make([]Node, 0)}
+	p.pos = start + 1 + end
+	if p.has(limit, " ") || p.has(limit, "\t") {
+		p.pos++
+		link.Nodes = p.parseNodes(limit, []string{"]", "\n"})
+	}
+	if !p.has(limit, "]") {
+		p.pos = start
+		return nil
+	}
+	p.pos++
+	link.span = p.span(start, p.pos)
+	return link
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range tagAttr.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+	return attrs
+}
+
+func (p *parser) parseTag(limit int) Node {
+	start := p.pos
+	match := tagOpen.FindStringSubmatch(p.src[start:limit])
+	if match == nil {
+		return nil
+	}
+	name := strings.ToLower(match[1])
+	if !knownTags[name] {
+		return nil
+	}
+	tag := &Tag{
+		Name:        name,
+		Attrs:       parseAttrs(match[2]),
+		SelfClosing: match[3] == "/" || voidTags[name],
+		Nodes:       make([]Node, 0),
+	}
+	p.pos += len(match[0])
+	if tag.SelfClosing {
+		tag.span = p.span(start, p.pos)
+		return tag
+	}
+	contentStart := p.pos
+	if rawTags[name] {
+		end := strings.Index(strings.ToLower(p.src[contentStart:limit]), "</"+name)
+		if end == -1 {
+			p.pos = start
+			return nil
+		}
+		p.pos = contentStart + end
+		if p.pos > contentStart {
+			tag.Nodes = append(tag.Nodes, p.text(contentStart, p.pos))
+		}
+	} else {
+		tag.Nodes = p.parseNodes(limit, []string{"</" + name, "</" + strings.ToUpper(name)})
+		if p.pos >= limit {
+			p.pos = start
+			return nil
+		}
+	}
+	end := strings.IndexByte(p.src[p.pos:limit], '>')
+	if end == -1 {
+		p.pos = start
+		return nil
+	}
+	p.pos += end + 1
+	tag.span = p.span(start, p.pos)
+	return tag
+}
+
+func (p *parser) parseList(limit int) Node {
+	start := p.pos
+	list := &List{}
+	var first, last *listItems
+	for {
+		itemStart := p.pos
+		if tail, ok := p.lists[memoKey{offset: itemStart, limit: limit}]; ok {
+			if last == nil {
+				first = p.items[tail]
+			} else {
+				last.next = p.items[tail]
+			}
+			p.pos = tail.End().Offset
+			break
+		}
+		for p.pos < limit && strings.IndexByte(listPrefixes, p.src[p.pos]) != -1 {
+			p.pos++
+		}
+		prefix := p.src[itemStart:p.pos]
+		nodes := p.parseNodes(limit, []string{"\n"})
+		items := &listItems{item: &ListItem{span: p.span(itemStart, p.pos), Prefix: prefix, Nodes: nodes}}
+		if last == nil {
+			first = items
+		} else {
+			last.next = items
+		}
+		last = items
+		if p.pos+1 < limit && strings.IndexByte(listPrefixes, p.src[p.pos+1]) != -1 {
+			p.pos++
+			continue
+		}
+		break
+	}
+	list.span = p.span(start, p.pos)
+	p.lists[memoKey{offset: start, limit: limit}] = list
+	p.items[list] = first
+	return list
+}
+
+func (p *parser) fillLists(nodes []Node) {
+	Inspect(nodes, func(node Node) bool {
+		if list, ok := node.(*List); ok && list.Items == nil {
+			list.Items = make([]*ListItem, 0)
+			for items := p.items[list]; items != nil; items = items.next {
+				list.Items = append(list.Items, items.item)
+			}
+		}
+		return true
+	})
+}
+
+func (p *parser) parseTable(limit int) Node {
+	start := p.pos
+	p.pos += 2
+	end := p.lineEnd(limit)
+	table := &Table{Attrs: strings.TrimSpace(p.src[p.pos:end]), Caption: make([]Node, 0), Rows: make([]*TableRow, 0)}
+	p.pos = end
+	var row *TableRow
+	for p.pos < limit {
+		if p.src[p.pos] == '\n' {
+			p.pos++
+		}
+		for p.pos < limit && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+			p.pos++
+		}
+		lineStart := p.pos
+		switch {
+		case p.has(limit, "|}"):
+			p.pos += 2
+			table.span = p.span(start, p.pos)
+			return table
+		case p.has(limit, "|+"):
+			p.pos += 2
+			table.Caption = p.parseNodes(limit, []string{"\n"})
+		case p.has(limit, "|-"):
+			p.pos = p.lineEnd(limit)
+			row = &TableRow{span: p.span(lineStart, p.pos), Attrs: strings.TrimSpace(p.src[lineStart+2 : p.pos]), Cells: make([]*TableCell, 0)}
+			table.Rows = append(table.Rows, row)
+		case p.has(limit, "|") || p.has(limit, "!"):
+			header := p.src[p.pos] == '!'
+			p.pos++
+			if row == nil {
+				row = &TableRow{span: p.span(lineStart, lineStart), Cells: make([]*TableCell, 0)}
+				table.Rows = append(table.Rows, row)
+			}
+			cellStart := lineStart
+			for {
+				row.Cells = append(row.Cells, p.parseCell(limit, header, cellStart))
+				row.end = p.position(p.pos)
+				if p.has(limit, "||") || (header && p.has(limit, "!!")) {
+					cellStart = p.pos
+					p.pos += 2
+					continue
+				}
+				break
+			}
+		default:
+			p.pos = p.lineEnd(limit)
+		}
+	}
+	table.span = p.span(start, p.pos)
+	return table
+}
+
+func (p *parser) parseCell(limit int, header bool, start int) *TableCell {
+	terminators := []string{"\n|", "\n!", "||"}
+	if header {
+		terminators = append(terminators, "!!")
+	}
+	cell := &TableCell{Header: header}
+	contentStart := p.pos
+	nodes := p.parseNodes(limit, append(terminators, "|"))
+	if text, ok := firstText(nodes); ok && len(nodes) == 1 && p.has(limit, "|") && !p.has(limit, "||") && !strings.Contains(text.Value, "\n") {
+		cell.Attrs = strings.TrimSpace(text.Value)
+		p.pos++
+	} else {
+		p.pos = contentStart
+	}
+	cell.Nodes = p.parseNodes(limit, terminators)
+	cell.span = p.span(start, p.pos)
+	return cell
+}
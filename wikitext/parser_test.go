@@ -0,0 +1,177 @@
+package wikitext
+
+import "fmt"
+import "strings"
+import "testing"
+import "time"
+
+const article = `{{Infobox person
+| name = Ada Lovelace <!-- full name -->
+| birth_date = {{birth date|1815|12|10}}
+| occupation = [[Mathematician]], writer
+}}
+'''Ada Lovelace''' was an English [[mathematician|mathematical]] writer.<ref name="toole">{{cite book |last=Toole |title=Ada}}</ref>
+
+== Early life ==
+She was born in [[London]].<ref name="toole" />
+* First item
+** Nested [http://example.com example]
+{| class="wikitable"
+|+ Facts
+! Year !! Event
+|-
+| 1815 || Born
+|-
+| style="color:red" | 1852 || Died
+|}
+<nowiki>[[not a link]]</nowiki>
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	doc := Parse(article)
+	infobox, ok := doc.Nodes[0].(*Template)
+	if !ok || infobox.Name != "Infobox person" {
+		t.Error(fmt.Sprintf("expected infobox template, got %#v", doc.Nodes[0]))
+		return
+	}
+	if len(infobox.Params) != 3 || infobox.Param("name").Raw != "Ada Lovelace <!-- full name -->" {
+		t.Error(fmt.Sprintf("got wrong infobox params %+v", infobox.Params))
+		return
+	}
+	birth, ok := infobox.Param("birth_date").Value[1].(*Template)
+	if !ok || birth.Name != "birth date" || birth.Param("2").Raw != "12" || !birth.Param("2").Positional {
+		t.Error("got wrong nested template")
+		return
+	}
+	if infobox.Pos().Line != 1 || infobox.End().Line != 5 {
+		t.Error(fmt.Sprintf("got wrong infobox position %+v %+v", infobox.Pos(), infobox.End()))
+		return
+	}
+
+	counts := make(map[string]int)
+	Inspect(doc.Nodes, func(node Node) bool {
+		counts[fmt.Sprintf("%T", node)]++
+		return true
+	})
+	expected := map[string]int{
+		"*wikitext.Template":     3,
+		"*wikitext.Link":         3,
+		"*wikitext.ExternalLink": 1,
+		"*wikitext.Heading":      1,
+		"*wikitext.List":         1,
+		"*wikitext.ListItem":     2,
+		"*wikitext.Table":        1,
+		"*wikitext.TableRow":     3,
+		"*wikitext.TableCell":    6,
+		"*wikitext.Tag":          3,
+		"*wikitext.Comment":      1,
+	}
+	for k, v := range expected {
+		if counts[k] != v {
+			t.Error(fmt.Sprintf("expected %d %s, got %d", v, k, counts[k]))
+		}
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	t.Parallel()
+	doc := Parse("Intro\n=== Sub ''section'' ===\nBody")
+	heading, ok := doc.Nodes[1].(*Heading)
+	if !ok || heading.Level != 3 || PlainText(heading.Nodes) != "Sub section" {
+		t.Error(fmt.Sprintf("got wrong heading %#v", doc.Nodes[1]))
+		return
+	}
+	if heading.Pos().Line != 2 || heading.Pos().Column != 1 || doc.Raw(heading) != "=== Sub ''section'' ===" {
+		t.Error(fmt.Sprintf("got wrong heading position %+v", heading.Pos()))
+		return
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	t.Parallel()
+	doc := Parse("{| class=\"wikitable\"\n! A !! B\n|-\n| style=\"color:red\" | 1 || [[x|y]]\n| 2\n|}")
+	table, ok := doc.Nodes[0].(*Table)
+	if !ok || table.Attrs != `class="wikitable"` || len(table.Rows) != 2 {
+		t.Error(fmt.Sprintf("got wrong table %#v", doc.Nodes[0]))
+		return
+	}
+	header := table.Rows[0]
+	if len(header.Cells) != 2 || !header.Cells[1].Header || PlainText(header.Cells[1].Nodes) != "B" {
+		t.Error("got wrong header row")
+		return
+	}
+	row := table.Rows[1]
+	if len(row.Cells) != 3 || row.Cells[0].Attrs != `style="color:red"` || PlainText(row.Cells[0].Nodes) != "1" || PlainText(row.Cells[1].Nodes) != "y" {
+		t.Error(fmt.Sprintf("got wrong row %+v", row.Cells))
+		return
+	}
+}
+
+func TestParseUnclosed(t *testing.T) {
+	t.Parallel()
+	doc := Parse("a {{b [[c <ref>d")
+	if len(doc.Nodes) != 1 || doc.Raw(doc.Nodes[0]) != "a {{b [[c <ref>d" {
+		t.Error(fmt.Sprintf("expected a single text node, got %#v", doc.Nodes))
+		return
+	}
+}
+
+func TestParseUnclosedNested(t *testing.T) {
+	t.Parallel()
+	inputs := []string{
+		strings.Repeat("{{a|", 22),
+		strings.Repeat("[[a|", 22),
+		strings.Repeat("<div style=\"x\">\n", 16),
+		strings.Repeat("{{a|[[b|<span>", 2000),
+		strings.Repeat("{{a|\n:", 4000),
+		strings.Repeat("{{a|\n* [[b|", 2000),
+	}
+	for _, input := range inputs {
+		start := time.Now()
+		doc := Parse(input)
+		if time.Since(start) > time.Second {
+			t.Error(fmt.Sprintf("parsing %q took %s", input[:16], time.Since(start)))
+			return
+		}
+		Inspect(doc.Nodes, func(node Node) bool {
+			switch node.(type) {
+			case *Template, *Link, *Tag:
+				t.Error(fmt.Sprintf("expected no closed constructs in %q, got %#v", input[:16], node))
+			}
+			return true
+		})
+	}
+}
+
+func TestParseNestedClosedOnce(t *testing.T) {
+	t.Parallel()
+	start := time.Now()
+	doc := Parse(strings.Repeat("{{a|", 2000) + "}}")
+	if time.Since(start) > time.Second {
+		t.Error(fmt.Sprintf("parsing took %s", time.Since(start)))
+		return
+	}
+	templates := 0
+	Inspect(doc.Nodes, func(node Node) bool {
+		if _, ok := node.(*Template); ok {
+			templates++
+		}
+		return true
+	})
+	if templates != 1 {
+		t.Error(fmt.Sprintf("expected only the innermost template, got %d", templates))
+		return
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	t.Parallel()
+	doc := Parse(article)
+	text := PlainText(doc.Nodes)
+	expected := "Ada Lovelace was an English mathematical writer.\n\nEarly life\n\nShe was born in London.\n\nFirst item\nNested example\n\nYear\tEvent\n1815\tBorn\n1852\tDied\n\n[[not a link]]"
+	if text != expected {
+		t.Error(fmt.Sprintf("got wrong plain text %q", text))
+		return
+	}
+}
@@ -0,0 +1,94 @@
+package wikitext
+
+import "regexp"
+import "strings"
+
+var emphasis = regexp.MustCompile(`'{2,}`)
+var spaces = regexp.MustCompile(` +`)
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+var mediaNamespaces = []string{"file:", "image:", "media:", "category:"}
+
+func isMedia(target string) bool {
+	target = strings.ToLower(strings.TrimSpace(target))
+	for _, namespace := range mediaNamespaces {
+		if strings.HasPrefix(target, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func StripEmphasis(text string) string {
+	return emphasis.ReplaceAllString(text, "")
+}
+
+func PlainText(nodes []Node) string {
+	return PlainTextWith(nodes, nil)
+}
+
+func PlainTextWith(nodes []Node, template func(*Template) string) string {
+	var text strings.Builder
+	writePlainText(&text, nodes, template)
+	lines := strings.Split(text.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaces.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func writePlainText(text *strings.Builder, nodes []Node, template func(*Template) string) {
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *Text:
+			text.WriteString(StripEmphasis(node.Value))
+		case *Template:
+			if template != nil {
+				text.WriteString(template(node))
+			}
+		case *Heading:
+			text.WriteString("\n")
+			writePlainText(text, node.Nodes, template)
+			text.WriteString("\n")
+		case *Link:
+			if isMedia(node.Target) {
+				continue
+			}
+			if len(node.Nodes) > 0 {
+				writePlainText(text, node.Nodes, template)
+			} else {
+				target, _, _ := strings.Cut(strings.TrimPrefix(node.Target, ":"), "#")
+				text.WriteString(target)
+			}
+		case *ExternalLink:
+			writePlainText(text, node.Nodes, template)
+		case *Tag:
+			switch node.Name {
+			case "ref", "references":
+			case "br":
+				text.WriteString("\n")
+			default:
+				writePlainText(text, node.Nodes, template)
+			}
+		case *List:
+			for _, item := range node.Items {
+				text.WriteString("\n")
+				writePlainText(text, item.Nodes, template)
+			}
+			text.WriteString("\n")
+		case *Table:
+			for _, row := range node.Rows {
+				text.WriteString("\n")
+				for i, cell := range row.Cells {
+					if i > 0 {
+						text.WriteString("\t")
+					}
+					var cellText strings.Builder
+					writePlainText(&cellText, cell.Nodes, template)
+					text.WriteString(strings.TrimSpace(cellText.String()))
+				}
+			}
+			text.WriteString("\n")
+		}
+	}
+}
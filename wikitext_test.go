@@ -0,0 +1,55 @@
+package wikipedia
+
+import "fmt"
+import "net/http"
+import "testing"
+
+func TestWikitext(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("rvslots") != "main" || q.Get("rvprop") != "content|ids|contentmodel" || q.Has("rvparse") {
+			t.Error(fmt.Sprintf("got wrong parameters %s", r.URL.RawQuery))
+		}
+		if q.Get("rvsection") == "2" {
+			fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":42,"parentid":41,"slots":{"main":{"contentmodel":"wikitext","contentformat":"text/x-wiki","content":"=== Early ===\nText."}}}]}]}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":42,"parentid":41,"slots":{"main":{"contentmodel":"wikitext","contentformat":"text/x-wiki","content":"'''A''' is a letter."}}}]}]}}`)
+	})
+	page := NewPage(w, "A")
+	wikitext, err := page.Wikitext()
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting wikitext %s", err))
+		return
+	}
+	if wikitext.Content != "'''A''' is a letter." || wikitext.RevisionId != 42 || wikitext.ContentModel != "wikitext" {
+		t.Error(fmt.Sprintf("got wrong wikitext %+v", wikitext))
+		return
+	}
+	wikitext, err = page.SectionWikitext(2)
+	if err != nil {
+		t.Error(fmt.Sprintf("error getting section wikitext %s", err))
+		return
+	}
+	if wikitext.Content != "=== Early ===\nText." {
+		t.Error(fmt.Sprintf("got wrong section wikitext %+v", wikitext))
+		return
+	}
+}
+
+func TestParseWikitext(t *testing.T) {
+	t.Parallel()
+	w := newTestWikipedia(t, func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, `{"query":{"pages":[{"pageid":1,"title":"A","revisions":[{"revid":42,"slots":{"main":{"contentmodel":"wikitext","content":"{{Short description|Letter}}\n'''A''' is a [[letter]]."}}}]}]}}`)
+	})
+	doc, err := NewPage(w, "A").ParseWikitext()
+	if err != nil {
+		t.Error(fmt.Sprintf("error parsing wikitext %s", err))
+		return
+	}
+	if len(doc.Nodes) != 4 {
+		t.Error(fmt.Sprintf("expected 4 nodes, got %d", len(doc.Nodes)))
+		return
+	}
+}